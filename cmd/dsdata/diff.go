@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/carterharrison/dsdata/datasheet"
+	"github.com/carterharrison/dsdata/diff"
+)
+
+// prints the stations that were added, removed or modified from the old release to the new one
+func runDiff (args []string) (err error) {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	format := flags.String("format", "text", "output format: text, json or patch")
+	output := flags.String("o", "", "file to write to, stdout when empty")
+	flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		return fmt.Errorf("diff needs the old and the new file")
+	}
+
+	if *format != "text" && *format != "json" && *format != "patch" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	old, err := readSheets(flags.Arg(0))
+
+	if err != nil {
+		return err
+	}
+
+	new, err := readSheets(flags.Arg(1))
+
+	if err != nil {
+		return err
+	}
+
+	stations, err := diff.Archives(old, new)
+
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+
+	if *output != "" {
+		var file io.WriteCloser
+
+		// not :=, the close below has to set the err that is returned
+		if file, err = createOutput(*output); err != nil {
+			return err
+		}
+
+		// a close that fails can leave the file cut short
+		defer func () {
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+
+		out = file
+	}
+
+	switch *format {
+	case "json": return json.NewEncoder(out).Encode(stations)
+	case "patch": return json.NewEncoder(out).Encode(diff.Patch(stations))
+	}
+
+	return diff.WriteText(out, stations)
+}
+
+// every sheet of the file or glob
+func readSheets (arg string) ([]datasheet.DataSheet, error) {
+	sheets := make([]datasheet.DataSheet, 0)
+
+	err := eachSheet([]string{arg}, datasheet.Pipeline{Ordered: true}, func (sheet datasheet.DataSheet) error {
+		sheets = append(sheets, sheet)
+		return nil
+	})
+
+	return sheets, err
+}
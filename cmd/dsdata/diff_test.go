@@ -0,0 +1,19 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDiffCloseError (t *testing.T) {
+	file := failOutput(t)
+	err := runDiff([]string{"-o", "changes.txt", "../../datasheet/testdata/first_order.txt", "../../datasheet/testdata/cors.txt"})
+
+	if !errors.Is(err, errClose) {
+		t.Errorf("got %v, want the close error", err)
+	}
+
+	if file.Len() == 0 {
+		t.Error("nothing was written before the close")
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/carterharrison/dsdata/datasheet"
+	"github.com/carterharrison/dsdata/export"
+)
+
+type exportOptions struct {
+	format string
+	properties []string
+	columns []string
+	history string
+	spc string
+}
+
+// makes the writer for the -format flag
+func newWriter (opts exportOptions, w io.Writer) (export.Writer, error) {
+	switch opts.format {
+	case "jsonl": return export.NewJSONLWriter(w), nil
+	case "dsdata": return export.NewDataSheetWriter(w), nil
+	case "geojson": return export.NewGeoJSONWriter(w, opts.properties)
+	case "csv", "tsv":
+		comma := ','
+
+		if opts.format == "tsv" {
+			comma = '\t'
+		}
+
+		writer, err := export.NewCSVWriter(w, opts.columns, comma)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if writer.History, err = export.ParsePick(opts.history); err != nil {
+			return nil, err
+		}
+
+		if writer.SPC, err = export.ParsePick(opts.spc); err != nil {
+			return nil, err
+		}
+
+		return writer, nil
+	}
+
+	return nil, fmt.Errorf("unknown format %q", opts.format)
+}
+
+// makes the file of the -o flag, the tests swap it for one that fails
+var createOutput = func (name string) (io.WriteCloser, error) {
+	return os.Create(name)
+}
+
+// "a,b" => [a b], "" => nil
+func splitList (s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(s, ",")
+}
+
+func runExport (args []string) (err error) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", "jsonl", "output format: jsonl, geojson, csv, tsv or dsdata")
+	properties := flags.String("properties", "", "geojson feature properties, comma separated, ex: id,metadata.DESIGNATION,monumentation._MARKER,lastCondition")
+	columns := flags.String("columns", "", "csv columns, comma separated, one of: " + strings.Join(export.CSVColumns(), ","))
+	history := flags.String("history", "latest", "csv history row for the history_* columns: latest, first or explode")
+	spc := flags.String("spc", "first", "csv state plane coordinates for the spc_* columns: latest, first or explode")
+	workers := flags.Int("workers", 0, "sheets parsed at once, the number of cpus when 0")
+	unordered := flags.Bool("unordered", false, "write the sheets as they are parsed instead of in the order of the files")
+	output := flags.String("o", "", "file to write to, stdout when empty")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		return fmt.Errorf("export needs at least one input file")
+	}
+
+	var out io.Writer = os.Stdout
+
+	if *output != "" {
+		var file io.WriteCloser
+
+		// not :=, the close below has to set the err that is returned
+		if file, err = createOutput(*output); err != nil {
+			return err
+		}
+
+		// a close that fails can leave the file cut short
+		defer func () {
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+
+		out = file
+	}
+
+	writer, err := newWriter(exportOptions{
+		format: *format,
+		properties: splitList(*properties),
+		columns: splitList(*columns),
+		history: *history,
+		spc: *spc,
+	}, out)
+
+	if err != nil {
+		return err
+	}
+
+	pipeline := datasheet.Pipeline{
+		Workers: *workers,
+		Ordered: !*unordered,
+	}
+
+	err = eachSheet(flags.Args(), pipeline, func (sheet datasheet.DataSheet) error {
+		return writer.Write(sheet)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+var errClose = errors.New("close failed")
+
+// takes the writes and fails on close, like a disk that fills up before the last flush
+type failingClose struct {
+	bytes.Buffer
+}
+
+func (file *failingClose) Close () error {
+	return errClose
+}
+
+// makes the -o files fail on close for the test
+func failOutput (t *testing.T) *failingClose {
+	file := &failingClose{}
+	create := createOutput
+
+	createOutput = func (name string) (io.WriteCloser, error) {
+		return file, nil
+	}
+
+	t.Cleanup(func () {
+		createOutput = create
+	})
+
+	return file
+}
+
+func TestExportCloseError (t *testing.T) {
+	file := failOutput(t)
+	err := runExport([]string{"-o", "sheets.jsonl", "../../datasheet/testdata/first_order.txt"})
+
+	if !errors.Is(err, errClose) {
+		t.Errorf("got %v, want the close error", err)
+	}
+
+	if file.Len() == 0 {
+		t.Error("nothing was written before the close")
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/carterharrison/dsdata/datasheet"
+	"github.com/carterharrison/dsdata/store"
+)
+
+// writes every sheet to a sqlite database, stations already in it are replaced
+func runImport (args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	path := flags.String("db", "datasheets.db", "sqlite database to write to, made when it does not exist")
+	batch := flags.Int("batch", 1000, "sheets written in each transaction")
+	workers := flags.Int("workers", 0, "sheets parsed at once, the number of cpus when 0")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		return fmt.Errorf("import needs at least one input file")
+	}
+
+	if *batch <= 0 {
+		*batch = 1
+	}
+
+	db, err := store.Open(*path)
+
+	if err != nil {
+		return err
+	}
+
+	defer db.Close()
+
+	sheets := make([]datasheet.DataSheet, 0, *batch)
+	imported := 0
+
+	flush := func () error {
+		if err := db.Put(sheets...); err != nil {
+			return err
+		}
+
+		imported += len(sheets)
+		sheets = sheets[:0]
+		return nil
+	}
+
+	err = eachSheet(flags.Args(), datasheet.Pipeline{Workers: *workers}, func (sheet datasheet.DataSheet) error {
+		// a sheet without a pid has nothing to key it on
+		if sheet.Id == "" {
+			return nil
+		}
+
+		sheets = append(sheets, sheet)
+
+		if len(sheets) < *batch {
+			return nil
+		}
+
+		return flush()
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if err := flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "imported %d sheets into %s\n", imported, *path)
+	return nil
+}
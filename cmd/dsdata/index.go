@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/carterharrison/dsdata/datasheet"
+)
+
+// writes a sidecar index next to every file with where each of its sheets is, ex: CA.txt.idx
+func runIndex (args []string) error {
+	flags := flag.NewFlagSet("index", flag.ExitOnError)
+	workers := flags.Int("workers", 0, "sheets parsed at once, the number of cpus when 0")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		return fmt.Errorf("index needs at least one input file")
+	}
+
+	paths, err := expandPaths(flags.Args())
+
+	if err != nil {
+		return err
+	}
+
+	inputs := make([]datasheet.Input, len(paths))
+
+	for i, path := range paths {
+		inputs[i] = datasheet.FileInput(path)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	index := datasheet.NewOffsetIndex()
+
+	if err := index.Load(datasheet.Pipeline{Workers: *workers, Ordered: true}.Run(ctx, inputs)); err != nil {
+		return err
+	}
+
+	if err := index.WriteSidecars(paths); err != nil {
+		return err
+	}
+
+	log.Printf("indexed %d sheets of %d files", index.Len(), len(paths))
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/carterharrison/dsdata/datasheet"
+)
+
+// expands the globs in the args, "DataSheets/*.txt" works even when the shell did not expand it
+func expandPaths (args []string) ([]string, error) {
+	paths := make([]string, 0, len(args))
+
+	for _, arg := range args {
+		matches, err := filepath.Glob(arg)
+
+		if err != nil {
+			return nil, err
+		}
+
+		// not a glob, or a glob that matched nothing, let open report it
+		if len(matches) == 0 {
+			matches = []string{arg}
+		}
+
+		paths = append(paths, matches...)
+	}
+
+	return paths, nil
+}
+
+// calls fn for every sheet in every file, sheets with parse errors are logged and still given to fn.
+// the files are parsed by the pipeline, fn is only called from one goroutine
+func eachSheet (args []string, pipeline datasheet.Pipeline, fn func (sheet datasheet.DataSheet) error) error {
+	paths, err := expandPaths(args)
+
+	if err != nil {
+		return err
+	}
+
+	if len(paths) == 0 {
+		return fmt.Errorf("no input files")
+	}
+
+	inputs := make([]datasheet.Input, len(paths))
+
+	for i, path := range paths {
+		inputs[i] = datasheet.FileInput(path)
+	}
+
+	// stops the pipeline when we return early
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for result := range pipeline.Run(ctx, inputs) {
+		if _, ok := result.Err.(datasheet.ParseErrors); ok {
+			// keep going, the rest of the file is still good
+			fmt.Fprintln(os.Stderr, result.File + ":", result.Err)
+		} else if result.Err != nil {
+			return fmt.Errorf("%s: %w", result.File, result.Err)
+		}
+
+		if err := fn(result.Sheet); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
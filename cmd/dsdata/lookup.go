@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/carterharrison/dsdata/datasheet"
+	"github.com/carterharrison/dsdata/export"
+)
+
+// writes the sheets of the pids, or designations, read from stdin one a line. only the sheets
+// asked for are parsed, through the sidecar indexes made by dsdata index
+func runLookup (args []string) error {
+	flags := flag.NewFlagSet("lookup", flag.ExitOnError)
+	format := flags.String("format", "jsonl", "output format: jsonl, geojson, csv, tsv or dsdata")
+	columns := flags.String("columns", "", "csv columns, comma separated, one of: " + strings.Join(export.CSVColumns(), ","))
+	pids := flags.String("pid", "", "pids to look up, comma separated, read from stdin a line each when empty")
+	designation := flags.Bool("designation", false, "look up designations instead of pids")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		return fmt.Errorf("lookup needs at least one indexed file")
+	}
+
+	paths, err := expandPaths(flags.Args())
+
+	if err != nil {
+		return err
+	}
+
+	index, err := datasheet.LoadSidecars(paths)
+
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%w, make it with dsdata index", err)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	writer, err := newWriter(exportOptions{format: *format, columns: splitList(*columns), history: "latest", spc: "first"}, os.Stdout)
+
+	if err != nil {
+		return err
+	}
+
+	lookup := func (key string) error {
+		found := []datasheet.PID{datasheet.PID(strings.ToUpper(key))}
+
+		if *designation {
+			found = found[:0]
+
+			for _, location := range index.Designation(key) {
+				found = append(found, location.PID)
+			}
+		}
+
+		for _, pid := range found {
+			sheet, err := index.LookupPID(pid)
+
+			if err == datasheet.ErrNotIndexed {
+				fmt.Fprintln(os.Stderr, pid + ": not found")
+				return nil
+			}
+
+			if _, ok := err.(datasheet.ParseErrors); ok {
+				fmt.Fprintln(os.Stderr, pid + ":", err)
+			} else if err != nil {
+				return fmt.Errorf("%s: %w", pid, err)
+			}
+
+			if err := writer.Write(sheet); err != nil {
+				return err
+			}
+		}
+
+		if len(found) == 0 {
+			fmt.Fprintln(os.Stderr, key + ": not found")
+		}
+
+		return nil
+	}
+
+	if *pids != "" {
+		for _, pid := range splitList(*pids) {
+			if err := lookup(pid); err != nil {
+				return err
+			}
+		}
+	} else if err := eachLine(os.Stdin, lookup); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+// calls fn with every line of r that is not blank, trimmed
+func eachLine (r io.Reader, fn func (line string) error) error {
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" {
+			continue
+		}
+
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
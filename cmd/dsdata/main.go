@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/carterharrison/dsdata/datasheet"
+)
+
+const usage = `dsdata parses datasheets (dsdata) of the national geodetic survey
+
+usage:
+	dsdata export [-format jsonl|geojson|csv|tsv|dsdata] [-properties list] [-columns list] [-workers n] [-unordered] [-o file] <file or glob>...
+	dsdata near [-lat deg] [-lon deg] [-radius m] [-k n] [-marker list] [-stability list] [-condition list] [-index file] [-save file] [<file or glob>...]
+	dsdata import [-db file] [-batch n] [-workers n] <file or glob>...
+	dsdata serve [-addr host:port] [-watch interval] [-workers n] <file or glob>...
+	dsdata diff [-format text|json|patch] [-o file] <old> <new>
+	dsdata sync [-url url] [-dir folder] [-match glob] [-verify]
+	dsdata index [-workers n] <file or glob>...
+	dsdata lookup [-format jsonl|geojson|csv|tsv|dsdata] [-columns list] [-pid list] [-designation] <file or glob>... < pids
+	dsdata <file>    prints the marks that have no marker type
+`
+
+func main () {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+
+	switch os.Args[1] {
+	case "export": err = runExport(os.Args[2:])
+	case "near": err = runNear(os.Args[2:])
+	case "import": err = runImport(os.Args[2:])
+	case "serve": err = runServe(os.Args[2:])
+	case "diff": err = runDiff(os.Args[2:])
+	case "sync": err = runSync(os.Args[2:])
+	case "index": err = runIndex(os.Args[2:])
+	case "lookup": err = runLookup(os.Args[2:])
+	case "help", "-h", "-help", "--help":
+		fmt.Print(usage)
+		return
+	default: err = runUnmarked(os.Args[1:])
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "dsdata:", err)
+		os.Exit(1)
+	}
+}
+
+// prints the name and position of the marks without a _MARKER
+func runUnmarked (args []string) error {
+	return eachSheet(args, datasheet.Pipeline{Ordered: true}, func (sheet datasheet.DataSheet) error {
+		// without a current position there is nothing to print
+		if sheet.Position == nil {
+			return nil
+		}
+
+		lat := sheet.Position.Lat
+		lng := sheet.Position.Lon
+		name := sheet.BasicMetadata["DESIGNATION"]
+
+		if sheet.Monumentation["_MARKER"] == "" {
+			fmt.Println(name, lat, lng)
+		}
+
+		return nil
+	})
+}
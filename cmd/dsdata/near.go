@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/carterharrison/dsdata/datasheet"
+	"github.com/carterharrison/dsdata/spatial"
+)
+
+// prints the marks near a point as json lines, the closest first
+func runNear (args []string) error {
+	flags := flag.NewFlagSet("near", flag.ExitOnError)
+	lat := flags.Float64("lat", 0, "latitude of the point, decimal degrees, south is negative")
+	lon := flags.Float64("lon", 0, "longitude of the point, decimal degrees, west is negative")
+	radius := flags.Float64("radius", 0, "meters around the point, the -k nearest marks when 0")
+	k := flags.Int("k", 0, "most marks printed, 0 for all within -radius or the 10 nearest without it")
+	markers := flags.String("marker", "", "marker codes to keep, comma separated, ex: DD,DB")
+	stabilities := flags.String("stability", "", "stability codes to keep, comma separated, ex: A,B")
+	conditions := flags.String("condition", "", "conditions of the latest recovery to keep, comma separated, ex: GOOD")
+	indexPath := flags.String("index", "", "index saved with -save to query instead of parsing files")
+	save := flags.String("save", "", "file to save the index built from the input files to")
+	flags.Parse(args)
+
+	var index *spatial.Index
+
+	if *indexPath != "" {
+		loaded, err := spatial.LoadFile(*indexPath)
+
+		if err != nil {
+			return err
+		}
+
+		index = loaded
+	} else {
+		if flags.NArg() == 0 {
+			return fmt.Errorf("near needs an -index or at least one input file")
+		}
+
+		index = spatial.NewIndex()
+
+		err := eachSheet(flags.Args(), datasheet.Pipeline{}, func (sheet datasheet.DataSheet) error {
+			index.Add(sheet)
+			return nil
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if *save != "" {
+		if err := index.SaveFile(*save); err != nil {
+			return err
+		}
+	}
+
+	filter := spatial.Filter{Conditions: splitList(*conditions)}
+
+	for _, code := range splitList(*markers) {
+		filter.Markers = append(filter.Markers, datasheet.Marker(code))
+	}
+
+	for _, code := range splitList(*stabilities) {
+		filter.Stabilities = append(filter.Stabilities, datasheet.Stability(code))
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+
+	for _, result := range nearResults(index, *lat, *lon, *radius, *k, filter) {
+		if err := encoder.Encode(result); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// every mark within the radius, or the k nearest without one. k caps the marks within the
+// radius only when it is set, 10 nearest when it is not and there is no radius
+func nearResults (index *spatial.Index, lat float64, lon float64, radius float64, k int, filter spatial.Filter) []spatial.Result {
+	if radius <= 0 {
+		if k <= 0 {
+			k = 10
+		}
+
+		return index.Nearest(lat, lon, k, filter)
+	}
+
+	results := index.Radius(lat, lon, radius, filter)
+
+	if k > 0 && len(results) > k {
+		results = results[:k]
+	}
+
+	return results
+}
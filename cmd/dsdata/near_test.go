@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/carterharrison/dsdata/datasheet"
+	"github.com/carterharrison/dsdata/spatial"
+)
+
+func TestNearResults (t *testing.T) {
+	index := spatial.NewIndex()
+
+	// 15 marks a few meters apart around the point and one far away
+	for i := 0; i < 15; i++ {
+		index.AddMark(spatial.Mark{PID: datasheet.PID(fmt.Sprintf("AB%04d", i)), Lat: 32.7 + float64(i) * 0.00001, Lon: -117.1})
+	}
+
+	index.AddMark(spatial.Mark{PID: "AB9999", Lat: 33.7, Lon: -117.1})
+
+	tests := []struct {
+		radius float64
+		k int
+		want int
+	}{
+		{1000, 0, 15},
+		{1000, 4, 4},
+		{0, 0, 10},
+		{0, 12, 12},
+		{0, 20, 16},
+	}
+
+	for _, tt := range tests {
+		if got := nearResults(index, 32.7, -117.1, tt.radius, tt.k, spatial.Filter{}); len(got) != tt.want {
+			t.Errorf("radius %v k %d: got %d marks, want %d", tt.radius, tt.k, len(got), tt.want)
+		}
+	}
+}
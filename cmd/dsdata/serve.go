@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/carterharrison/dsdata/datasheet"
+	"github.com/carterharrison/dsdata/server"
+)
+
+// serves the sheets of the files over http until it is killed
+func runServe (args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	watch := flags.Duration("watch", 30 * time.Second, "how often to check the files and reload them when they changed, 0 to never")
+	workers := flags.Int("workers", 0, "sheets parsed at once, the number of cpus when 0")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		return fmt.Errorf("serve needs at least one input file")
+	}
+
+	paths, err := expandPaths(flags.Args())
+
+	if err != nil {
+		return err
+	}
+
+	s := &server.Server{Paths: paths, Pipeline: datasheet.Pipeline{Workers: *workers}}
+
+	if err := s.Reload(context.Background()); err != nil {
+		return err
+	}
+
+	if *watch > 0 {
+		go s.Watch(context.Background(), *watch)
+	}
+
+	log.Printf("serving %d sheets on http://%s", s.Archive().Len(), *addr)
+	return http.ListenAndServe(*addr, s)
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/carterharrison/dsdata/mirror"
+)
+
+// fetches the files of the archive that changed since the last sync into a folder
+func runSync (args []string) error {
+	flags := flag.NewFlagSet("sync", flag.ExitOnError)
+	source := flags.String("url", mirror.Archive, "ftp or http url of the folder of the archive")
+	dir := flags.String("dir", "DataSheets", "folder to write the files and the manifest to")
+	match := flags.String("match", "", "glob the names of the files to fetch are matched with, ex: *.zip, every file when empty")
+	verify := flags.Bool("verify", false, "hash the files already fetched and fetch them again when they changed")
+	flags.Parse(args)
+
+	if flags.NArg() != 0 {
+		return fmt.Errorf("sync takes no files, only flags")
+	}
+
+	m := &mirror.Mirror{URL: *source, Dir: *dir, Match: *match, Verify: *verify}
+	report, err := m.Sync(context.Background())
+
+	log.Printf("%d fetched, %d resumed, %d unchanged, %d bytes", len(report.Fetched), len(report.Resumed), len(report.Skipped), report.Bytes)
+	return err
+}
@@ -0,0 +1,208 @@
+package datasheet
+
+import (
+	"regexp"
+	"strings"
+)
+
+// what a line is for telling where the sheets start
+type lineKind int
+
+const (
+	dataLine lineKind = iota
+
+	// a new page, a 1 in the carriage control column, a form feed, the
+	// National Geodetic Survey title or the banner of the web retrieval
+	pageLine
+
+	// DATASHEETS - PROGRAM = datasheet95, VERSION = 8.12.5.3
+	programLine
+
+	// the row of stars after the pid that opens every sheet
+	starLine
+)
+
+// the program and version of a program line, the DATASHEETS - in front is left off by some
+// retrievals and older ones put the DATABASE first
+var programPattern = regexp.MustCompile(`^\s*(?:DATASHEETS\s*-\s*)?(?:DATABASE\s*=[^,]*,\s*)?PROGRAM\s*=\s*([^,\s]+)(?:\s*,\s*VERSION\s*=\s*([^,\s]+))?`)
+
+// the starts of the lines the web retrieval puts before every sheet, older ones point to
+// dsdata.txt and newer ones to dsdata.pdf
+var bannerLines = []string{
+	"The NGS Data Sheet",
+	"See file dsdata.",
+}
+
+// a line of the input as the sheets are split on it
+type sheetLine struct {
+	kind lineKind
+
+	// the line given to the page, without a form feed and with the carriage control
+	// column put back when the file does not have one
+	text string
+
+	// the line started with a form feed, it starts a page even when the rest is data
+	formFeed bool
+}
+
+// sorts the lines of one input. the first line with a pid tells whether the input has the
+// carriage control column, when it does not every line after gets the column back so the
+// columns of the page line up, whatever follows the pid. ex: HV4612.See and HV4612_MARKER
+type lineClassifier struct {
+	// a line with a pid was seen and noControl is known
+	decided bool
+
+	// the pids are at the start of the lines
+	noControl bool
+}
+
+// sorts the line into its kind
+func (classifier *lineClassifier) classify (raw string) sheetLine {
+	line := sheetLine{kind: dataLine, text: raw}
+
+	if strings.HasPrefix(line.text, "\f") {
+		line.text = strings.TrimLeft(line.text, "\f")
+		line.formFeed = true
+	}
+
+	if !classifier.decided {
+		switch {
+		case startsWithPID(line.text, 0): classifier.decided, classifier.noControl = true, true
+		case startsWithPID(line.text, 1) && line.text[0] == ' ': classifier.decided = true
+		}
+	}
+
+	if classifier.noControl && line.text != "" {
+		line.text = " " + line.text
+	}
+
+	switch {
+	case strings.HasPrefix(line.text, "1"): line.kind = pageLine
+	case strings.HasPrefix(strings.TrimSpace(line.text), "National Geodetic Survey"): line.kind = pageLine
+	case isBannerLine(line.text): line.kind = pageLine
+	case programPattern.MatchString(line.text): line.kind = programLine
+	case isStarLine(line.text): line.kind = starLine
+	case line.formFeed && strings.TrimSpace(line.text) == "": line.kind = pageLine
+	}
+
+	return line
+}
+
+// whether the line ends the sheet before it, a data line after a form feed does too
+func (line sheetLine) header () bool {
+	return line.kind != dataLine || line.formFeed
+}
+
+// whether the line has a pid at the column, two letters and four digits, ex: HV4612, with a
+// space or the stars after it
+func hasPID (line string, col int) bool {
+	if !pidAt(line, col) {
+		return false
+	}
+
+	return len(line) == col + 6 || line[col + 6] == ' ' || line[col + 6] == '*'
+}
+
+// whether the line has a pid at the column with anything but a letter or digit after it,
+// ex: HV4612.See or HV4612_MARKER
+func startsWithPID (line string, col int) bool {
+	if !pidAt(line, col) {
+		return false
+	}
+
+	if len(line) == col + 6 {
+		return true
+	}
+
+	c := line[col + 6]
+	return !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9')
+}
+
+// two letters and four digits at the column
+func pidAt (line string, col int) bool {
+	if len(line) < col + 6 {
+		return false
+	}
+
+	for i, c := range []byte(line[col:col + 6]) {
+		if i < 2 && (c < 'A' || c > 'Z') || i >= 2 && (c < '0' || c > '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// " HV4612 *****", the pid and a row of stars
+func isStarLine (line string) bool {
+	if !hasPID(line, 1) {
+		return false
+	}
+
+	stars := strings.TrimSpace(line[7:])
+	return len(stars) >= 3 && strings.Trim(stars, "*") == ""
+}
+
+func isBannerLine (line string) bool {
+	line = strings.TrimSpace(line)
+
+	for _, banner := range bannerLines {
+		if strings.HasPrefix(line, banner) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// the program and version of a program line
+func parseProgram (text string) (string, string) {
+	match := programPattern.FindStringSubmatch(text)
+
+	if match == nil {
+		return "", ""
+	}
+
+	return match[1], match[2]
+}
+
+// finds where the sheets start, the Reader and the Pipeline split the same way with it.
+// a sheet starts at the first of a run of header lines, so a 1 line, the program line and
+// the stars of one sheet are one start, blank lines between them too. a file that does not
+// open with a header starts at its first line with a pid, the lines before it are skipped
+type sheetSplitter struct {
+	// the last line was a header, the next one is part of the same start
+	inHeader bool
+
+	// a sheet was started
+	started bool
+}
+
+// takes the next line and says whether it starts a sheet and whether it is data for the
+// page, the header lines and the blank lines between them are not
+func (splitter *sheetSplitter) next (line sheetLine) (bool, bool) {
+	if line.header() {
+		start := !splitter.inHeader
+		splitter.started = true
+		splitter.inHeader = line.kind != dataLine
+
+		return start, line.kind == dataLine
+	}
+
+	if !splitter.started {
+		if !hasPID(line.text, 1) {
+			return false, false
+		}
+
+		splitter.started = true
+		splitter.inHeader = false
+		return true, true
+	}
+
+	if splitter.inHeader && strings.TrimSpace(line.text) == "" {
+		return false, false
+	}
+
+	splitter.inHeader = false
+	return false, true
+}
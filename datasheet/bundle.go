@@ -0,0 +1,187 @@
+package datasheet
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+)
+
+// the first bytes of each kind of input, the tar magic is 257 bytes in
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh")
+	zipMagic = []byte("PK\x03\x04")
+	emptyZipMagic = []byte("PK\x05\x06")
+	tarMagic = []byte("ustar")
+	tarMagicOffset = 257
+)
+
+// Unpack calls fn with every file of r, in order. gzip and bzip2 are taken off first, then a
+// zip or tar is opened and fn is called with each of its .txt and .html members by their name
+// in the bundle, ex: DS_ARCHIVE/CA.txt. anything else is a single file, fn is called once
+// with an empty member name. a web page is given to fn as its text, see StripHTML. an error
+// from fn stops the unpacking and is returned
+func Unpack (r io.Reader, fn func (member string, r io.Reader) error) error {
+	buffered := bufio.NewReader(r)
+
+	// a short input is fine, it just is not a bundle
+	magic, _ := buffered.Peek(tarMagicOffset + len(tarMagic))
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gz, err := gzip.NewReader(buffered)
+
+		if err != nil {
+			return err
+		}
+
+		defer gz.Close()
+		return Unpack(gz, fn)
+	case bytes.HasPrefix(magic, bzip2Magic): return Unpack(bzip2.NewReader(buffered), fn)
+	case bytes.HasPrefix(magic, zipMagic) || bytes.HasPrefix(magic, emptyZipMagic): return unpackZip(r, buffered, fn)
+	case len(magic) >= tarMagicOffset + len(tarMagic) && bytes.Equal(magic[tarMagicOffset:], tarMagic): return unpackTar(buffered, fn)
+	case isHTML(magic):
+		text, err := StripHTML(buffered)
+
+		if err != nil {
+			return err
+		}
+
+		return fn("", text)
+	}
+
+	return fn("", buffered)
+}
+
+// whether the first bytes of an input are of one Unpack takes apart or strips
+func packed (magic []byte) bool {
+	if isHTML(magic) {
+		return true
+	}
+
+	for _, prefix := range [][]byte{gzipMagic, bzip2Magic, zipMagic, emptyZipMagic} {
+		if bytes.HasPrefix(magic, prefix) {
+			return true
+		}
+	}
+
+	return len(magic) >= tarMagicOffset + len(tarMagic) && bytes.Equal(magic[tarMagicOffset:tarMagicOffset + len(tarMagic)], tarMagic)
+}
+
+// a zip needs to read from anywhere in it, a file is read in place, anything else in memory
+func unpackZip (r io.Reader, buffered io.Reader, fn func (member string, r io.Reader) error) error {
+	var at io.ReaderAt
+	var size int64
+
+	if file, ok := r.(*os.File); ok {
+		info, err := file.Stat()
+
+		if err != nil {
+			return err
+		}
+
+		at, size = file, info.Size()
+	} else {
+		data, err := io.ReadAll(buffered)
+
+		if err != nil {
+			return err
+		}
+
+		at, size = bytes.NewReader(data), int64(len(data))
+	}
+
+	archive, err := zip.NewReader(at, size)
+
+	if err != nil {
+		return err
+	}
+
+	for _, f := range archive.File {
+		if f.FileInfo().IsDir() || !isSheetFile(f.Name) {
+			continue
+		}
+
+		member, err := f.Open()
+
+		if err != nil {
+			return err
+		}
+
+		err = unpackMember(f.Name, member, fn)
+		member.Close()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func unpackTar (r io.Reader, fn func (member string, r io.Reader) error) error {
+	archive := tar.NewReader(r)
+
+	for {
+		header, err := archive.Next()
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if header.Typeflag != tar.TypeReg || !isSheetFile(header.Name) {
+			continue
+		}
+
+		if err := unpackMember(header.Name, archive, fn); err != nil {
+			return err
+		}
+	}
+}
+
+// gives fn the member, as its text when it is a web page
+func unpackMember (name string, r io.Reader, fn func (member string, r io.Reader) error) error {
+	buffered := bufio.NewReader(r)
+	magic, _ := buffered.Peek(tarMagicOffset + len(tarMagic))
+
+	if !isHTML(magic) {
+		return fn(name, buffered)
+	}
+
+	text, err := StripHTML(buffered)
+
+	if err != nil {
+		return err
+	}
+
+	return fn(name, text)
+}
+
+// the members of a bundle with sheets in them
+func isSheetFile (name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasSuffix(name, ".txt") || strings.HasSuffix(name, ".html") || strings.HasSuffix(name, ".htm")
+}
+
+// the name of a member for provenance, ex: ds.tar.gz/DS_ARCHIVE/CA.txt
+func memberPath (input string, member string) string {
+	if member == "" {
+		return input
+	}
+
+	if input == "" {
+		return member
+	}
+
+	return input + "/" + member
+}
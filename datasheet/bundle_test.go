@@ -0,0 +1,211 @@
+package datasheet
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// the corpus files in the order they are bundled
+var bundled = []string{"testdata/bench_marks.txt", "testdata/cors.txt", "testdata/first_order.txt"}
+
+func gzipped (t *testing.T, data []byte) []byte {
+	var out bytes.Buffer
+	gz := gzip.NewWriter(&out)
+
+	if _, err := gz.Write(data); err != nil {
+		t.Fatal(err)
+	}
+
+	gz.Close()
+	return out.Bytes()
+}
+
+// the bundled files in a tar or zip, with a readme that is skipped
+func bundle (t *testing.T, kind string) []byte {
+	var out bytes.Buffer
+	tw := tar.NewWriter(&out)
+	zw := zip.NewWriter(&out)
+
+	add := func (name string, data []byte) {
+		if kind == "tar" {
+			tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg})
+			tw.Write(data)
+			return
+		}
+
+		w, _ := zw.Create(name)
+		w.Write(data)
+	}
+
+	add("DS_ARCHIVE/README", []byte("not a datasheet\n"))
+
+	for _, name := range bundled {
+		data, err := os.ReadFile(name)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		add("DS_ARCHIVE/" + filepath.Base(name), data)
+	}
+
+	if kind == "tar" {
+		tw.Close()
+	} else {
+		zw.Close()
+	}
+
+	return out.Bytes()
+}
+
+func bytesInput (name string, data []byte) Input {
+	return Input{
+		Name: name,
+		Open: func () (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(data)), nil
+		},
+	}
+}
+
+// the sheets of the pipeline over the input, with provenance
+func runInput (t *testing.T, input Input) []Result {
+	t.Helper()
+	results := make([]Result, 0)
+
+	for result := range (Pipeline{Ordered: true, RecordProvenance: true}).Run(context.Background(), []Input{input}) {
+		if result.Err != nil {
+			t.Fatal(result.Err)
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func TestUnpack (t *testing.T) {
+	want := readCorpus(t, bundled)
+
+	for i := range want {
+		want[i].Provenance = nil
+	}
+
+	members := []string{"DS_ARCHIVE/bench_marks.txt", "DS_ARCHIVE/bench_marks.txt", "DS_ARCHIVE/cors.txt", "DS_ARCHIVE/first_order.txt"}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"tar", bundle(t, "tar")},
+		{"tar.gz", gzipped(t, bundle(t, "tar"))},
+		{"zip", bundle(t, "zip")},
+	}
+
+	for _, tt := range tests {
+		results := runInput(t, bytesInput("archive." + tt.name, tt.data))
+
+		if len(results) != len(want) {
+			t.Errorf("%s: got %d sheets, want %d", tt.name, len(results), len(want))
+			continue
+		}
+
+		for i, result := range results {
+			if result.Sheet.Member != members[i] || result.File != "archive." + tt.name {
+				t.Errorf("%s: sheet %d is from %s %s, want %s", tt.name, i, result.File, result.Sheet.Member, members[i])
+			}
+
+			if file := result.Sheet.Provenance["id"].File; file != "archive." + tt.name + "/" + members[i] {
+				t.Errorf("%s: sheet %d has provenance from %s", tt.name, i, file)
+			}
+
+			result.Sheet.Member = ""
+			result.Sheet.Provenance = nil
+
+			if !reflect.DeepEqual(result.Sheet, want[i]) {
+				t.Errorf("%s: sheet %d is not %s", tt.name, i, want[i].Id)
+			}
+		}
+	}
+}
+
+func TestUnpackSingleFile (t *testing.T) {
+	want := readCorpus(t, []string{"testdata/bench_marks.txt"})
+	data, err := os.ReadFile("testdata/bench_marks.txt")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inputs := []Input{
+		bytesInput("bench_marks.txt.gz", gzipped(t, data)),
+		FileInput("testdata/bench_marks.txt.bz2"),
+	}
+
+	for _, input := range inputs {
+		results := runInput(t, input)
+
+		if len(results) != len(want) {
+			t.Errorf("%s: got %d sheets, want %d", input.Name, len(results), len(want))
+			continue
+		}
+
+		for i, result := range results {
+			if result.Sheet.Member != "" {
+				t.Errorf("%s: got member %q for a single file", input.Name, result.Sheet.Member)
+			}
+
+			// the offsets are of the uncompressed text
+			if got, want := result.Sheet.Provenance, want[i].Provenance; len(got) != len(want) || got["id"].Offset != want["id"].Offset {
+				t.Errorf("%s: sheet %d has other provenance", input.Name, i)
+			}
+		}
+	}
+}
+
+func TestUnpackZipFile (t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "archive.zip")
+
+	if err := os.WriteFile(path, bundle(t, "zip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+	members := make([]string, 0)
+
+	err = Unpack(file, func (member string, r io.Reader) error {
+		members = append(members, member)
+		reader := NewReader(r)
+		reader.Member = member
+
+		sheet, err := reader.Read()
+
+		if err != nil || sheet.Member != member {
+			t.Errorf("%s: got %s %v", member, sheet.Member, err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"DS_ARCHIVE/bench_marks.txt", "DS_ARCHIVE/cors.txt", "DS_ARCHIVE/first_order.txt"}; !reflect.DeepEqual(members, want) {
+		t.Errorf("got members %v, want %v", members, want)
+	}
+}
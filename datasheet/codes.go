@@ -0,0 +1,278 @@
+package datasheet
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// CodeInfo is a row of a code table of dsdata.pdf. the tables only have the codes seen on
+// sheets so far, a code that is not in one is kept as it is and Info says it is not known
+type CodeInfo struct {
+	// as written on the sheet, ex: DD
+	Code string `json:"code"`
+
+	// ex: SURVEY DISK
+	Short string `json:"short"`
+
+	Long string `json:"long"`
+}
+
+type CodeTable []CodeInfo
+
+// Lookup finds the row by its code, short or long description, the case and spaces around s do not matter
+func (table CodeTable) Lookup (s string) (CodeInfo, bool) {
+	s = strings.ToUpper(trimWhiteSpace(s))
+
+	for _, info := range table {
+		if s == info.Code || s == strings.ToUpper(info.Short) || s == strings.ToUpper(info.Long) {
+			return info, true
+		}
+	}
+
+	return CodeInfo{}, false
+}
+
+// Marker is the _MARKER of the monumentation, ex: DD
+type Marker string
+
+const (
+	MarkerBenchMarkDisk Marker = "DB"
+	MarkerSurveyDisk Marker = "DD"
+	MarkerTraverseStationDisk Marker = "DE"
+	MarkerGravityStationDisk Marker = "DG"
+	MarkerHorizontalControlDisk Marker = "DH"
+	MarkerTidalStationDisk Marker = "DJ"
+	MarkerMagneticStationDisk Marker = "DM"
+	MarkerNotSpecified Marker = "DO"
+	MarkerCalibrationBaseLineDisk Marker = "DQ"
+	MarkerReferenceMarkDisk Marker = "DR"
+	MarkerTriangulationStationDisk Marker = "DS"
+	MarkerTidalBenchMarkDisk Marker = "DT"
+	MarkerVerticalControlDisk Marker = "DV"
+	MarkerAzimuthMarkDisk Marker = "DZ"
+	MarkerBolt Marker = "B"
+	MarkerFlangeEncasedRod Marker = "F"
+	MarkerDrillHole Marker = "H"
+	MarkerMetalRod Marker = "I"
+	MarkerNail Marker = "N"
+	MarkerRivet Marker = "R"
+	MarkerChiseledSquare Marker = "X"
+	MarkerSeeDescription Marker = "Z"
+)
+
+var MarkerCodes = CodeTable{
+	{"DB", "BENCH MARK DISK", "BENCH MARK DISK"},
+	{"DD", "SURVEY DISK", "SURVEY DISK"},
+	{"DE", "TRAVERSE STATION DISK", "TRAVERSE STATION DISK"},
+	{"DG", "GRAVITY STATION DISK", "GRAVITY STATION DISK"},
+	{"DH", "HORIZONTAL CONTROL DISK", "HORIZONTAL CONTROL DISK"},
+	{"DJ", "TIDAL STATION DISK", "TIDAL STATION DISK"},
+	{"DM", "MAGNETIC STATION DISK", "MAGNETIC STATION DISK"},
+	{"DO", "NOT SPECIFIED", "NOT SPECIFIED OR SEE DESCRIPTION"},
+	{"DQ", "CALIBRATION BASE LINE DISK", "CALIBRATION BASE LINE DISK"},
+	{"DR", "REFERENCE MARK DISK", "REFERENCE MARK DISK"},
+	{"DS", "TRIANGULATION STATION DISK", "TRIANGULATION STATION DISK"},
+	{"DT", "TIDAL BENCH MARK DISK", "TIDAL BENCH MARK DISK"},
+	{"DV", "VERTICAL CONTROL DISK", "VERTICAL CONTROL DISK"},
+	{"DZ", "AZIMUTH MARK DISK", "AZIMUTH MARK DISK"},
+	{"B", "BOLT", "BOLT"},
+	{"F", "FLANGE-ENCASED ROD", "FLANGE-ENCASED ROD"},
+	{"H", "DRILL HOLE", "DRILL HOLE"},
+	{"I", "METAL ROD", "METAL ROD"},
+	{"N", "NAIL", "NAIL"},
+	{"R", "RIVET", "RIVET"},
+	{"X", "CHISELED SQUARE", "CHISELED SQUARE"},
+	{"Z", "SEE DESCRIPTION", "SEE DESCRIPTION"},
+}
+
+func (m Marker) Info () (CodeInfo, bool) { return MarkerCodes.Lookup(string(m)) }
+func (m Marker) String () string { return codeString(MarkerCodes, string(m)) }
+func (m Marker) MarshalJSON () ([]byte, error) { return marshalCode(MarkerCodes, string(m)) }
+func (m *Marker) UnmarshalJSON (data []byte) error { return unmarshalCode(data, (*string)(m)) }
+
+// Setting is the _SETTING of the monumentation, ex: 7
+type Setting string
+
+const (
+	SettingUnspecified Setting = "0"
+	SettingConcreteMonument Setting = "7"
+	SettingLightStructure Setting = "30"
+	SettingMassiveStructure Setting = "32"
+	SettingMetalMast Setting = "36"
+	SettingRodWithoutSleeve Setting = "49"
+	SettingRodInSleeve Setting = "59"
+	SettingRockOutcrop Setting = "66"
+)
+
+var SettingCodes = CodeTable{
+	{"0", "UNSPECIFIED", "UNSPECIFIED SETTING"},
+	{"7", "CONCRETE MONUMENT", "SET IN TOP OF CONCRETE MONUMENT"},
+	{"30", "LIGHT STRUCTURE", "SET IN A LIGHT STRUCTURE"},
+	{"32", "MASSIVE STRUCTURE", "SET IN A MASSIVE STRUCTURE"},
+	{"36", "METAL MAST/ROD", "METAL MAST/ROD"},
+	{"49", "ROD W/O SLEEVE", "STAINLESS STEEL ROD W/O SLEEVE (10 FT.+)"},
+	{"59", "ROD IN SLEEVE", "STAINLESS STEEL ROD IN SLEEVE (10 FT.+)"},
+	{"66", "ROCK OUTCROP", "SET IN ROCK OUTCROP"},
+}
+
+func (s Setting) Info () (CodeInfo, bool) { return SettingCodes.Lookup(string(s)) }
+func (s Setting) String () string { return codeString(SettingCodes, string(s)) }
+func (s Setting) MarshalJSON () ([]byte, error) { return marshalCode(SettingCodes, string(s)) }
+func (s *Setting) UnmarshalJSON (data []byte) error { return unmarshalCode(data, (*string)(s)) }
+
+// Stability is the _STABILITY of the monumentation, A is the most stable
+type Stability string
+
+const (
+	StabilityMostReliable Stability = "A"
+	StabilityProbablyHold Stability = "B"
+	StabilityMayHold Stability = "C"
+	StabilityQuestionable Stability = "D"
+)
+
+var StabilityCodes = CodeTable{
+	{"A", "MOST RELIABLE", "MOST RELIABLE AND EXPECTED TO HOLD POSITION/ELEVATION WELL"},
+	{"B", "PROBABLY HOLD", "PROBABLY HOLD POSITION/ELEVATION WELL"},
+	{"C", "MAY HOLD", "MAY HOLD, BUT OF TYPE COMMONLY SUBJECT TO SURFACE MOTION"},
+	{"D", "QUESTIONABLE", "MARK OF QUESTIONABLE OR UNKNOWN STABILITY"},
+}
+
+func (s Stability) Info () (CodeInfo, bool) { return StabilityCodes.Lookup(string(s)) }
+func (s Stability) String () string { return codeString(StabilityCodes, string(s)) }
+func (s Stability) MarshalJSON () ([]byte, error) { return marshalCode(StabilityCodes, string(s)) }
+func (s *Stability) UnmarshalJSON (data []byte) error { return unmarshalCode(data, (*string)(s)) }
+
+// Order is the order of accuracy of a survey, the orders AA, A and B are for horizontal networks
+type Order string
+
+const (
+	OrderAA Order = "AA"
+	OrderA Order = "A"
+	OrderB Order = "B"
+	OrderFirst Order = "FIRST"
+	OrderSecond Order = "SECOND"
+	OrderThird Order = "THIRD"
+	OrderFourth Order = "FOURTH"
+	OrderFifth Order = "FIFTH"
+)
+
+var OrderCodes = CodeTable{
+	{"AA", "ORDER AA", "ORDER AA, 0.3 CM + 1:100,000,000"},
+	{"A", "ORDER A", "ORDER A, 0.5 CM + 1:10,000,000"},
+	{"B", "ORDER B", "ORDER B, 0.8 CM + 1:1,000,000"},
+	{"FIRST", "1", "FIRST ORDER"},
+	{"SECOND", "2", "SECOND ORDER"},
+	{"THIRD", "3", "THIRD ORDER"},
+	{"FOURTH", "4", "FOURTH ORDER"},
+	{"FIFTH", "5", "FIFTH ORDER"},
+}
+
+func (o Order) Info () (CodeInfo, bool) { return OrderCodes.Lookup(string(o)) }
+func (o Order) String () string { return codeString(OrderCodes, string(o)) }
+func (o Order) MarshalJSON () ([]byte, error) { return marshalCode(OrderCodes, string(o)) }
+func (o *Order) UnmarshalJSON (data []byte) error { return unmarshalCode(data, (*string)(o)) }
+
+// OrderClass is an order with its class, ex: FIRST CLASS II
+type OrderClass struct {
+	Order Order `json:"order"`
+
+	// 0, I or II, empty when the order has no classes
+	Class string `json:"class,omitempty"`
+}
+
+// HeightMethod is how a superseded ellipsoid height was found, ex: GP
+type HeightMethod string
+
+const (
+	HeightMethodGPS HeightMethod = "GP"
+)
+
+var HeightMethodCodes = CodeTable{
+	{"GP", "GPS", "GPS OBSERVATIONS"},
+}
+
+func (h HeightMethod) Info () (CodeInfo, bool) { return HeightMethodCodes.Lookup(string(h)) }
+func (h HeightMethod) String () string { return codeString(HeightMethodCodes, string(h)) }
+func (h HeightMethod) MarshalJSON () ([]byte, error) { return marshalCode(HeightMethodCodes, string(h)) }
+func (h *HeightMethod) UnmarshalJSON (data []byte) error { return unmarshalCode(data, (*string)(h)) }
+
+// the long description, or the code when it is not in the table
+func codeString (table CodeTable, code string) string {
+	if info, ok := table.Lookup(code); ok {
+		return info.Long
+	}
+
+	return code
+}
+
+// {"code": "DD", "description": "SURVEY DISK"}, without the description when the code is not known
+func marshalCode (table CodeTable, code string) ([]byte, error) {
+	value := struct {
+		Code string `json:"code"`
+		Description string `json:"description,omitempty"`
+	}{Code: code}
+
+	if info, ok := table.Lookup(code); ok {
+		value.Description = info.Long
+	}
+
+	return json.Marshal(value)
+}
+
+// takes the object from marshalCode or a plain string of the code
+func unmarshalCode (data []byte, code *string) error {
+	if err := json.Unmarshal(data, code); err == nil {
+		return nil
+	}
+
+	value := struct {
+		Code string `json:"code"`
+	}{}
+
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	*code = value.Code
+	return nil
+}
+
+// "FIRST     CLASS II" => FIRST, II
+func parseOrderClass (s string) OrderClass {
+	parts := strings.SplitN(trimWhiteSpace(s), "CLASS", 2)
+	order := OrderClass{Order: Order(trimWhiteSpace(parts[0]))}
+
+	if len(parts) == 2 {
+		order.Class = trimWhiteSpace(parts[1])
+	}
+
+	return order
+}
+
+// the orders and classes by their number on the sheet, order 1 is FIRST and class 1 is I
+var (
+	numberedOrders = []Order{OrderFirst, OrderSecond, OrderThird, OrderFourth, OrderFifth}
+	numberedClasses = []string{"0", "I", "II"}
+)
+
+// the order and class numbers of a superseded height, ex: 1 2 => FIRST, II
+func numberedOrderClass (nums []float64) (OrderClass, bool) {
+	orders := numberedOrders
+	classes := numberedClasses
+
+	if len(nums) == 0 || nums[0] < 1 || int(nums[0]) > len(orders) || float64(int(nums[0])) != nums[0] {
+		return OrderClass{}, false
+	}
+
+	order := OrderClass{Order: orders[int(nums[0]) - 1]}
+
+	if len(nums) > 1 {
+		if nums[1] < 0 || int(nums[1]) >= len(classes) || float64(int(nums[1])) != nums[1] {
+			return OrderClass{}, false
+		}
+
+		order.Class = classes[int(nums[1])]
+	}
+
+	return order, true
+}
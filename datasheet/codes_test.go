@@ -0,0 +1,100 @@
+package datasheet
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCodeTableLookup (t *testing.T) {
+	tests := []struct {
+		table CodeTable
+		s string
+		want string
+		ok bool
+	}{
+		{MarkerCodes, "DD", "DD", true},
+		{MarkerCodes, "survey disk", "DD", true},
+		{StabilityCodes, "MAY HOLD, BUT OF TYPE COMMONLY SUBJECT TO SURFACE MOTION", "C", true},
+		{SettingCodes, " 7 ", "7", true},
+		{OrderCodes, "1", "FIRST", true},
+		{MarkerCodes, "QQ", "", false},
+	}
+
+	for _, tt := range tests {
+		info, ok := tt.table.Lookup(tt.s)
+
+		if ok != tt.ok || info.Code != tt.want {
+			t.Errorf("Lookup(%q) = %q %v, want %q %v", tt.s, info.Code, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCodeJSON (t *testing.T) {
+	monument := Monument{MarkerType: MarkerSurveyDisk, StabilityType: Stability("Q")}
+	data, err := json.Marshal(monument)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"markerType":{"code":"DD","description":"SURVEY DISK"},"stabilityType":{"code":"Q"}}`
+
+	if string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+
+	var got Monument
+
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	deepEqual(t, got, monument)
+
+	if err := json.Unmarshal([]byte(`{"markerType":"DB"}`), &got); err != nil || got.MarkerType != MarkerBenchMarkDisk {
+		t.Errorf("got %q %v, want the plain code", got.MarkerType, err)
+	}
+
+	if MarkerSurveyDisk.String() != "SURVEY DISK" || Marker("QQ").String() != "QQ" {
+		t.Errorf("got %q and %q", MarkerSurveyDisk, Marker("QQ"))
+	}
+}
+
+func TestParseOrderClass (t *testing.T) {
+	deepEqual(t, parseOrderClass("FIRST     CLASS II"), OrderClass{Order: OrderFirst, Class: "II"})
+	deepEqual(t, parseOrderClass("A"), OrderClass{Order: OrderA})
+
+	order, ok := numberedOrderClass([]float64{2, 0})
+	deepEqual(t, order, OrderClass{Order: OrderSecond, Class: "0"})
+
+	if _, ok = numberedOrderClass([]float64{9}); ok {
+		t.Error("order 9 is not an order")
+	}
+}
+
+func TestUnknownCode (t *testing.T) {
+	page := NewPage()
+	page.CurrentSection = monumentationSection
+	page.LineNum = 1
+	page.AddLine(" AB1234_MARKER: QQ = NEW KIND OF MARK")
+	page.AddLine(" AB1234_SETTING: 66 = SET IN ROCK OUTCROP")
+
+	// a code missing from the tables is not a problem with the sheet
+	if len(page.Errors) != 0 {
+		t.Fatalf("got %v for an unknown code", page.Errors)
+	}
+
+	monument := page.CurrentSheet.Monument
+
+	if monument.MarkerType != "QQ" || monument.Marker != "NEW KIND OF MARK" || page.CurrentSheet.Monumentation["_MARKER"] != "QQ = NEW KIND OF MARK" {
+		t.Errorf("the unknown code was not kept: %+v", monument)
+	}
+
+	if _, ok := monument.MarkerType.Info(); ok {
+		t.Error("QQ is known")
+	}
+
+	if monument.SettingType != SettingRockOutcrop || monument.SettingType.String() != "SET IN ROCK OUTCROP" {
+		t.Errorf("got setting %q", monument.SettingType)
+	}
+}
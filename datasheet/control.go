@@ -0,0 +1,269 @@
+package datasheet
+
+import (
+	"strconv"
+	"strings"
+)
+
+// items of the survey control we know how to parse, matched on the end of the item
+var (
+	positionItem = "POSITION"
+	ellipHeightItem = "ELLIP HT"
+	epochItem = "EPOCH"
+	orthoHeightItem = "ORTHO HEIGHT"
+	geoidHeightItem = "GEOID HEIGHT"
+	laplaceItem = "LAPLACE CORR"
+	deflectionItem = "DEFLEC"
+
+	// the hemisphere after each half of a position, taken out to leave the numbers
+	hemispheres = strings.NewReplacer("(N)", " ", "(S)", " ", "(E)", " ", "(W)", " ")
+)
+
+// parses the value of a survey control line into the typed fields of the sheet
+func (page *Page) surveyControl (survey Survey) {
+	sheet := &page.CurrentSheet
+	item := survey.Item
+
+	switch {
+	case strings.HasSuffix(item, positionItem):
+		lat, lon, ok := parseLatLon(survey.Value)
+
+		// a position that did not parse is left off, not put at 0, 0
+		if !ok {
+			page.addError(ErrPosition)
+			return
+		}
+
+		pos := Position{Lat: lat, Lon: lon, Source: survey.By, Raw: survey.Value}
+		pos.Datum, pos.Realization = splitDatum(strings.TrimSuffix(item, positionItem))
+		sheet.Position = &pos
+		page.mark("position")
+
+	case strings.HasSuffix(item, epochItem):
+		nums := getNumbersFromString(survey.Value)
+
+		// the epoch is of the position line before it
+		if len(nums) > 0 && sheet.Position != nil {
+			sheet.Position.Epoch = nums[0]
+			page.mark("position")
+		}
+
+	case strings.HasSuffix(item, ellipHeightItem):
+		nums := getNumbersFromString(beforePar(survey.Value))
+
+		if len(nums) != 1 {
+			page.addError(ErrHeight)
+			return
+		}
+
+		datum, realization := splitDatum(strings.TrimSuffix(item, ellipHeightItem))
+		unit, date := parValues(survey.Value)
+
+		sheet.EllipsoidHeight = &EllipsoidHeight{
+			Height: nums[0],
+			Unit: unit,
+			Datum: datum,
+			Realization: realization,
+			Date: date,
+			Source: survey.By,
+			Raw: survey.Value,
+		}
+
+		page.mark("ellipsoidHeight")
+
+	case strings.HasSuffix(item, orthoHeightItem):
+		units := strings.Split(survey.Value, ")")
+		nums := getNumbersFromString(beforePar(survey.Value))
+
+		if len(nums) != 1 {
+			page.addError(ErrHeight)
+			return
+		}
+
+		height := &OrthometricHeight{
+			Height: nums[0],
+			Unit: getInnerParValue(survey.Value),
+			Datum: trimWhiteSpace(strings.TrimSuffix(item, orthoHeightItem)),
+			Source: survey.By,
+			Raw: survey.Value,
+		}
+
+		// the feet are after the meters, "350.04  (meters)    1148.4   (feet)"
+		if len(units) > 1 && getInnerParValue(units[1] + ")") == "feet" {
+			feet := getNumbersFromString(beforePar(units[1]))
+
+			if len(feet) == 1 {
+				height.Feet = feet[0]
+			}
+		}
+
+		sheet.OrthometricHeight = height
+		page.mark("orthometricHeight")
+
+	case item == geoidHeightItem:
+		nums := getNumbersFromString(beforePar(survey.Value))
+
+		if len(nums) != 1 {
+			page.addError(ErrHeight)
+			return
+		}
+
+		sheet.GeoidHeight = &GeoidHeight{
+			Height: nums[0],
+			Unit: getInnerParValue(survey.Value),
+			Model: survey.By,
+			Raw: survey.Value,
+		}
+
+		page.mark("geoidHeight")
+
+	case item == laplaceItem:
+		nums := getNumbersFromString(beforePar(survey.Value))
+
+		if len(nums) != 1 {
+			return
+		}
+
+		sheet.LaplaceCorrection = &LaplaceCorrection{
+			Seconds: nums[0],
+			Model: survey.By,
+			Raw: survey.Value,
+		}
+
+		page.mark("laplaceCorrection")
+
+	case strings.HasPrefix(item, deflectionItem):
+		nums := getNumbersFromString(beforePar(survey.Value))
+
+		if len(nums) != 1 {
+			return
+		}
+
+		if sheet.DeflectionOfVertical == nil {
+			sheet.DeflectionOfVertical = &DeflectionOfVertical{Raw: make([]string, 0)}
+		}
+
+		dov := sheet.DeflectionOfVertical
+		dov.Model = survey.By
+		dov.Raw = append(dov.Raw, survey.Value)
+		page.mark("deflectionOfVertical")
+
+		if strings.Contains(item, "XI") || strings.Contains(item, "N-S") {
+			dov.Xi = nums[0]
+		} else if strings.Contains(item, "ETA") || strings.Contains(item, "E-W") {
+			dov.Eta = nums[0]
+		}
+
+	case len(item) > 2 && item[len(item) - 2:len(item) - 1] == " ":
+		// NAD 83(2011) X, NAD 83(2011) Y and NAD 83(2011) Z
+		axis := item[len(item) - 1:]
+
+		if axis != "X" && axis != "Y" && axis != "Z" {
+			return
+		}
+
+		nums := getNumbersFromString(beforePar(survey.Value))
+
+		if len(nums) != 1 {
+			return
+		}
+
+		if sheet.XYZ == nil {
+			datum, realization := splitDatum(item[:len(item) - 2])
+
+			sheet.XYZ = &XYZ{
+				Unit: getInnerParValue(survey.Value),
+				Datum: datum,
+				Realization: realization,
+				Raw: make([]string, 0),
+			}
+		}
+
+		switch axis {
+		case "X": sheet.XYZ.X = nums[0]
+		case "Y": sheet.XYZ.Y = nums[0]
+		case "Z": sheet.XYZ.Z = nums[0]
+		}
+
+		sheet.XYZ.Raw = append(sheet.XYZ.Raw, survey.Value)
+		page.mark("xyz")
+	}
+}
+
+// "34 03 22.84466(N) 118 14 37.44855(W)" => 34.056346, -118.243735. every field has to be a
+// number in range, a misread coordinate is worse than none
+func parseLatLon (s string) (float64, float64, bool) {
+	fields := strings.Fields(hemispheres.Replace(s))
+
+	if len(fields) != 6 {
+		return 0, 0, false
+	}
+
+	nums := make([]float64, len(fields))
+
+	for i, field := range fields {
+		n, err := strconv.ParseFloat(field, 64)
+
+		if err != nil || n < 0 {
+			return 0, 0, false
+		}
+
+		nums[i] = n
+	}
+
+	if nums[1] >= 60 || nums[2] >= 60 || nums[4] >= 60 || nums[5] >= 60 {
+		return 0, 0, false
+	}
+
+	lat := DegreesMinutesSeconds(nums[0], nums[1], nums[2])
+	lon := DegreesMinutesSeconds(nums[3], nums[4], nums[5])
+
+	if lat > 90 || lon > 180 {
+		return 0, 0, false
+	}
+
+	if strings.Contains(s, "(S)") {
+		lat = -lat
+	}
+
+	if strings.Contains(s, "(W)") {
+		lon = -lon
+	}
+
+	return lat, lon, true
+}
+
+// "NAD 83(2011)" => "NAD 83", "2011"
+func splitDatum (s string) (string, string) {
+	s = trimWhiteSpace(s)
+	i := strings.Index(s, "(")
+
+	if i < 0 {
+		return s, ""
+	}
+
+	return trimWhiteSpace(s[:i]), getInnerParValue(s[i:])
+}
+
+// "322.493 (meters)        (06/27/12)" => "meters", "06/27/12"
+func parValues (s string) (string, string) {
+	parts := strings.SplitN(s, ")", 2)
+	first := getInnerParValue(parts[0] + ")")
+
+	if len(parts) < 2 {
+		return first, ""
+	}
+
+	return first, getInnerParValue(parts[1])
+}
+
+// everything before the first (
+func beforePar (s string) string {
+	i := strings.Index(s, "(")
+
+	if i < 0 {
+		return s
+	}
+
+	return s[:i]
+}
@@ -0,0 +1,504 @@
+package datasheet
+
+type DataSheet struct {
+	// the pid of the datasheet, located on the right side
+	Id string `json:"id"`
+
+	// the key value pairs at the top of the sheet
+	BasicMetadata map[string]string `json:"metadata"`
+
+	// surveys that are on going
+	NewSurveyControl []Survey `json:"newSurveys"`
+
+	// past surveys
+	OldSurveyControl []Survey `json:"oldSurveys"`
+
+	// the values of the survey control, parsed, nil if the sheet does not have them
+	Position *Position `json:"position,omitempty"`
+	EllipsoidHeight *EllipsoidHeight `json:"ellipsoidHeight,omitempty"`
+	OrthometricHeight *OrthometricHeight `json:"orthometricHeight,omitempty"`
+	GeoidHeight *GeoidHeight `json:"geoidHeight,omitempty"`
+	XYZ *XYZ `json:"xyz,omitempty"`
+	LaplaceCorrection *LaplaceCorrection `json:"laplaceCorrection,omitempty"`
+	DeflectionOfVertical *DeflectionOfVertical `json:"deflectionOfVertical,omitempty"`
+
+	// the accuracy section
+	Accuracy Accuracy `json:"accuracy"`
+
+	DeterminationMethodology []string `json:"determinationMethodology"`
+
+	StatePlaneCoordinates []StatePlaneCoordinates `json:"statePlaneCoordinates"`
+
+	SpatialAddress string `json:"spatialAddress"`
+
+	PrimaryAzimuthMarks []PrimaryAzimuthMark `json:"primaryAzimuthMark"`
+
+	ReferenceObjects []ReferenceObject `json:"referenceObjects"`
+
+	SurveyLatitudeLongitudes []SurveyLatitudeLongitude `json:"surveyLatitudeLongitudes"`
+
+	SurveyEllipsoidHeights []SurveyEllipsoidHeight `json:"surveyEllipsoidHeight"`
+
+	SurveyOrthometricHeights []SurveyOrthometricHeight `json:"surveyOrthometricHeight"`
+
+	// every monumentation item by its key, ex: _MARKER, with the + lines joined on
+	Monumentation map[string]string `json:"monumentation"`
+
+	// the monumentation items parsed, nil if the sheet has none
+	Monument *Monument `json:"monument,omitempty"`
+
+	History []History `json:"history"`
+
+	StationDescription []StationDescription `json:"stationDescription"`
+
+	StationRecoveries []StationRecovery `json:"stationRecoveries"`
+
+	// the program that made the sheet and its version, from a program line before it, ex:
+	// DATASHEETS - PROGRAM = datasheet95, VERSION = 8.12.5.3
+	Program string `json:"program,omitempty"`
+	ProgramVersion string `json:"programVersion,omitempty"`
+
+	// the file in a bundled input the sheet was read from, ex: DS_ARCHIVE/CA.txt, empty for a plain input
+	Member string `json:"member,omitempty"`
+
+	// where each record came from keyed by its json path, ex: history[2] or metadata.DESIGNATION.
+	// only filled when Page.RecordProvenance is on
+	Provenance map[string]Provenance `json:"provenance,omitempty"`
+}
+
+type Monument struct {
+	// DD, SURVEY DISK
+	MarkerCode string `json:"markerCode,omitempty"`
+	Marker string `json:"marker,omitempty"`
+
+	// 7, SET IN TOP OF CONCRETE MONUMENT
+	SettingCode string `json:"settingCode,omitempty"`
+	Setting string `json:"setting,omitempty"`
+
+	// the _SP_SET, ex: CONCRETE POST
+	SpecificSetting string `json:"specificSetting,omitempty"`
+
+	Stamping string `json:"stamping,omitempty"`
+	MarkLogo string `json:"markLogo,omitempty"`
+	Projection string `json:"projection,omitempty"`
+
+	// N, NO MAGNETIC MATERIAL
+	MagneticCode string `json:"magneticCode,omitempty"`
+	Magnetic string `json:"magnetic,omitempty"`
+
+	// B, PROBABLY HOLD POSITION/ELEVATION WELL
+	StabilityCode string `json:"stabilityCode,omitempty"`
+	Stability string `json:"stability,omitempty"`
+
+	// the codes typed with the tables of dsdata.pdf, ex: MarkerSurveyDisk
+	MarkerType Marker `json:"markerType,omitempty"`
+	SettingType Setting `json:"settingType,omitempty"`
+	StabilityType Stability `json:"stabilityType,omitempty"`
+
+	Satellite *Satellite `json:"satellite,omitempty"`
+	RodDepth *Depth `json:"rodDepth,omitempty"`
+	SleeveDepth *Depth `json:"sleeveDepth,omitempty"`
+}
+
+// if the site was reported as suitable for satellite observations, and when
+type Satellite struct {
+	Suitable bool `json:"suitable"`
+
+	// as written on the sheet, ex: April 20, 2004
+	Date string `json:"date"`
+}
+
+type Depth struct {
+	Value float64 `json:"value"`
+	Unit string `json:"unit"`
+}
+
+type StationDescription struct {
+	Description string `json:"description"`
+}
+
+type StationRecovery struct {
+	Date string `json:"date"`
+	Description string `json:"description"`
+}
+
+type History struct {
+	Date string `json:"date"`
+	Condition string `json:"condition"`
+	By string `json:"by"`
+}
+
+type SurveyOrthometricHeight struct {
+	Date string `json:"date"`
+	Height float64 `json:"height"`
+	Unit string `json:"unit"`
+	Method string `json:"method"`
+	Order []float64 `json:"order"`
+
+	// the order and class numbers decoded, nil if they are not known
+	Accuracy *OrderClass `json:"accuracy,omitempty"`
+
+	// NAVD 88 or NGVD 29
+	Datum string `json:"datum"`
+
+	// the model the height was converted or computed with, ex: VERTCON, empty when adjusted
+	Model string `json:"model,omitempty"`
+
+	// the height in both units, from the sheet or converted with the u.s. survey foot
+	Meters float64 `json:"meters"`
+	Feet float64 `json:"feet"`
+}
+
+type SurveyEllipsoidHeight struct {
+	Date string `json:"date"`
+	Height float64 `json:"height"`
+	Unit string `json:"unit"`
+	Method string `json:"method"`
+	Order string `json:"order"`
+
+	// the code of the method, ex: GP of GP(2007.00)
+	Technique HeightMethod `json:"technique,omitempty"`
+
+	// the adjustment of the method, ex: 2007.00 of GP(2007.00)
+	Adjustment string `json:"adjustment,omitempty"`
+
+	// the order and class numbers decoded, nil if they are not known
+	Accuracy *OrderClass `json:"accuracy,omitempty"`
+
+	// the height in both units, converted with the u.s. survey foot
+	Meters float64 `json:"meters"`
+	Feet float64 `json:"feet"`
+}
+
+
+type SurveyLatitudeLongitude struct {
+	Name string `json:"name"`
+	Pos string `json:"pos"`
+	Body string `json:"body"`
+	Order string `json:"order"`
+}
+
+type PrimaryAzimuthMark struct {
+	Mark string `json:"mark"`
+	GridAz []float64 `json:"gridAz"`
+
+	// the grid the azimuth is on, SPC or UTM and its zone, ex: CA 6
+	System string `json:"system"`
+	Zone string `json:"zone"`
+
+	// the grid azimuth in decimal degrees
+	Azimuth float64 `json:"azimuth"`
+
+	// the mark in the reference objects, empty if it is not listed with a pid
+	Station PID `json:"station,omitempty"`
+}
+
+type ReferenceObject struct {
+	// as on the sheet, empty for marks without a pid like reference marks
+	Pid string `json:"pid"`
+
+	Ref string `json:"ref"`
+
+	Distance string `json:"distance"`
+
+	GeodAz string `json:"geodAz"`
+
+	// the pid when it is a valid one, to look the station up
+	Station PID `json:"station,omitempty"`
+
+	// the distance parsed, nil if the sheet has none
+	Length *Distance `json:"length,omitempty"`
+
+	// the geodetic azimuth in decimal degrees, nil if the sheet has none
+	Azimuth *float64 `json:"azimuth,omitempty"`
+}
+
+// PID is the permanent identifier of a station, two letters and four digits, ex: HV4612
+type PID string
+
+// Valid is true when the pid has the form of one
+func (pid PID) Valid () bool {
+	if len(pid) != 6 {
+		return false
+	}
+
+	for i, c := range pid {
+		letter := c >= 'A' && c <= 'Z'
+		digit := c >= '0' && c <= '9'
+
+		if (i < 2 && !letter) || (i >= 2 && !digit) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// 12.395 METERS or APPROX. 5.6 KM
+type Distance struct {
+	Value float64 `json:"value"`
+	Unit string `json:"unit"`
+	Approximate bool `json:"approximate"`
+
+	// the distance converted from the unit
+	Meters float64 `json:"meters"`
+}
+
+type Accuracy struct {
+	// key as HORZ ORDER
+	HorzOrder []string `json:"horzOrder"`
+
+	// key as ELLP ORDER
+	EllpOrder []string `json:"ellpOrder"`
+
+	// key as VERT ORDER
+	VertOrder []string `json:"vertOrder"`
+
+	// key as NETWORK
+	Network []NetworkAccuracy `json:"network"`
+
+	// the orders decoded
+	Horizontal []OrderClass `json:"horizontal,omitempty"`
+	Ellipsoid []OrderClass `json:"ellipsoid,omitempty"`
+	Vertical []OrderClass `json:"vertical,omitempty"`
+}
+
+// a row of the projections, state plane or utm coordinates
+type StatePlaneCoordinates struct {
+	North float64	`json:"north"`
+	East float64	`json:"east"`
+
+	// MT, sFT or iFT
+	Units string	`json:"units"`
+
+	// the scale factor
+	Scale float64	`json:"scale"`
+
+	// the degrees of the convergence, the sign of the convergence is on it
+	Factor float64	`json:"factor"`
+
+	// the minutes and seconds of the convergence
+	Converg []float64	`json:"converg"`
+
+	// the estimated accuracy in parentheses of a scaled row, ex: (+/- 180 meters Scaled)
+	Estimated string	`json:"estimated"`
+
+	// SPC or UTM
+	System string `json:"system"`
+
+	// the zone of the system, ex: CA 6 or 11
+	Zone string `json:"zone"`
+
+	// the convergence in decimal degrees
+	Convergence float64 `json:"convergence"`
+
+	// a * was next to the scale factor or the convergence
+	ScaleFlagged bool `json:"scaleFlagged,omitempty"`
+	ConvergenceFlagged bool `json:"convergenceFlagged,omitempty"`
+
+	// the north and east converted to meters from the units
+	NorthMeters float64 `json:"northMeters"`
+	EastMeters float64 `json:"eastMeters"`
+
+	// the estimated accuracy parsed, nil if the row has none
+	Accuracy *EstimatedAccuracy `json:"accuracy,omitempty"`
+}
+
+// (+/- 180 meters Scaled)
+type EstimatedAccuracy struct {
+	Value float64 `json:"value"`
+	Unit string `json:"unit"`
+
+	// how the coordinates were found, ex: Scaled
+	Method string `json:"method"`
+}
+
+type NetworkAccuracy struct {
+	Horiz float64 `json:"horiz"`
+	Ellip float64 `json:"ellip"`
+	SDN float64 `json:"SDN"`
+	SDE float64 `json:"SDE"`
+	SDH float64 `json:"SDH"`
+	CorrNE float64 `json:"corrNE"`
+}
+
+// NAD 83(2011) POSITION- 41 18 25.40218(N) 096 03 37.68433(W)   ADJUSTED
+type Position struct {
+	// decimal degrees, south is negative
+	Lat float64 `json:"lat"`
+
+	// decimal degrees, west is negative
+	Lon float64 `json:"lon"`
+
+	// ex: NAD 83
+	Datum string `json:"datum"`
+
+	// ex: 2011, the part in the ()
+	Realization string `json:"realization"`
+
+	// from the EPOCH line, 0 when not given
+	Epoch float64 `json:"epoch"`
+
+	// how it was found, ex: ADJUSTED, SCALED
+	Source string `json:"source"`
+
+	// the value as written on the sheet
+	Raw string `json:"raw"`
+}
+
+// NAD 83(2011) ELLIP HT-   322.493 (meters)        (06/27/12)   ADJUSTED
+type EllipsoidHeight struct {
+	Height float64 `json:"height"`
+	Unit string `json:"unit"`
+	Datum string `json:"datum"`
+	Realization string `json:"realization"`
+	Date string `json:"date"`
+	Source string `json:"source"`
+	Raw string `json:"raw"`
+}
+
+// NAVD 88 ORTHO HEIGHT -   350.04  (meters)    1148.4   (feet) ADJUSTED
+type OrthometricHeight struct {
+	Height float64 `json:"height"`
+	Unit string `json:"unit"`
+
+	// the height in feet when the sheet lists it next to the meters
+	Feet float64 `json:"feet"`
+
+	// ex: NAVD 88
+	Datum string `json:"datum"`
+	Source string `json:"source"`
+	Raw string `json:"raw"`
+}
+
+// GEOID HEIGHT    -         -27.57  (meters)                   GEOID12A
+type GeoidHeight struct {
+	Height float64 `json:"height"`
+	Unit string `json:"unit"`
+
+	// ex: GEOID12A
+	Model string `json:"model"`
+	Raw string `json:"raw"`
+}
+
+// earth centered earth fixed coordinates from the X, Y and Z lines
+type XYZ struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+	Unit string `json:"unit"`
+	Datum string `json:"datum"`
+	Realization string `json:"realization"`
+
+	// the X, Y and Z values as written on the sheet
+	Raw []string `json:"raw"`
+}
+
+// LAPLACE CORR    -          -1.60  (seconds)                  DEFLEC12A
+type LaplaceCorrection struct {
+	Seconds float64 `json:"seconds"`
+
+	// ex: DEFLEC12A
+	Model string `json:"model"`
+	Raw string `json:"raw"`
+}
+
+// deflection of the vertical, in seconds, only on sheets that list it
+type DeflectionOfVertical struct {
+	// north south component
+	Xi float64 `json:"xi"`
+
+	// east west component
+	Eta float64 `json:"eta"`
+
+	Model string `json:"model"`
+	Raw []string `json:"raw"`
+}
+
+type Survey struct {
+	// the type of survey
+	Item string `json:"item"`
+
+	// the value of the survey
+	Value string	`json:"value"`
+
+	// how the survey was collected
+	By string	`json:"by"`
+}
+
+func (datasheet *DataSheet) Init () {
+	datasheet.BasicMetadata = make(map[string]string)
+	datasheet.NewSurveyControl = make([]Survey, 0)
+	datasheet.OldSurveyControl = make([]Survey, 0)
+	datasheet.DeterminationMethodology = make([]string, 0)
+	datasheet.StatePlaneCoordinates = make([]StatePlaneCoordinates, 0)
+	datasheet.PrimaryAzimuthMarks = make([]PrimaryAzimuthMark, 0)
+	datasheet.ReferenceObjects = make([]ReferenceObject, 0)
+	datasheet.SurveyLatitudeLongitudes = make([]SurveyLatitudeLongitude, 0)
+	datasheet.SurveyEllipsoidHeights = make([]SurveyEllipsoidHeight, 0)
+	datasheet.SurveyOrthometricHeights = make([]SurveyOrthometricHeight, 0)
+	datasheet.Monumentation = make(map[string]string)
+	datasheet.History = make([]History, 0)
+	datasheet.StationDescription = make([]StationDescription, 0)
+	datasheet.StationRecoveries = make([]StationRecovery, 0)
+
+	acc := Accuracy{}
+	acc.Init()
+	datasheet.Accuracy = acc
+}
+
+func (accuracy *Accuracy) Init () {
+	accuracy.HorzOrder = make([]string, 0)
+	accuracy.EllpOrder = make([]string, 0)
+	accuracy.VertOrder = make([]string, 0)
+	accuracy.Network = make([]NetworkAccuracy, 0)
+}
+
+// LatestHistory is the most recent row of the history, dates like 1934 count as the start of the year.
+// false when the sheet has no history
+func (datasheet *DataSheet) LatestHistory () (History, bool) {
+	latest := -1
+	latestDate := ""
+
+	for i, history := range datasheet.History {
+		date := historyDate(history.Date)
+
+		// rows on the same date are in order, so the later row wins
+		if latest < 0 || date >= latestDate {
+			latest = i
+			latestDate = date
+		}
+	}
+
+	if latest < 0 {
+		return History{}, false
+	}
+
+	return datasheet.History[latest], true
+}
+
+// 1934 => 19340000 so it sorts with 20080312
+func historyDate (date string) string {
+	for len(date) < 8 {
+		date = date + "0"
+	}
+
+	return date
+}
+
+// ReferencedPIDs are the stations the sheet links to in its reference objects, each once and in order
+func (datasheet *DataSheet) ReferencedPIDs () []PID {
+	pids := make([]PID, 0)
+	seen := make(map[PID]bool)
+
+	for _, ref := range datasheet.ReferenceObjects {
+		if ref.Station == "" || ref.Station == PID(datasheet.Id) || seen[ref.Station] {
+			continue
+		}
+
+		seen[ref.Station] = true
+		pids = append(pids, ref.Station)
+	}
+
+	return pids
+}
@@ -0,0 +1,68 @@
+package datasheet
+
+import (
+	"testing"
+)
+
+func TestLatestHistory (t *testing.T) {
+	tests := []struct {
+		history []History
+		want History
+		ok bool
+	}{
+		{nil, History{}, false},
+		{
+			[]History{{Date: "1934", Condition: "MONUMENTED"}, {Date: "20080312", Condition: "GOOD"}},
+			History{Date: "20080312", Condition: "GOOD"},
+			true,
+		},
+		{
+			[]History{{Date: "20120812", Condition: "DESTROYED"}, {Date: "1959", Condition: "GOOD"}},
+			History{Date: "20120812", Condition: "DESTROYED"},
+			true,
+		},
+		{
+			[]History{{Date: "1959", Condition: "GOOD"}, {Date: "1959", Condition: "MARK NOT FOUND"}},
+			History{Date: "1959", Condition: "MARK NOT FOUND"},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		sheet := DataSheet{History: tt.history}
+		got, ok := sheet.LatestHistory()
+
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("LatestHistory of %v = %v %v, want %v %v", tt.history, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestReferencedPIDs (t *testing.T) {
+	sheet := DataSheet{
+		Id: "HV4612",
+		ReferenceObjects: []ReferenceObject{
+			{Pid: "HV4613", Station: "HV4613"},
+			{Ref: "MOUNT HOPE RM 1"},
+			{Pid: "HV4599", Station: "HV4599"},
+			{Pid: "HV4613", Station: "HV4613"},
+			{Pid: "HV4612", Station: "HV4612"},
+		},
+	}
+
+	got := sheet.ReferencedPIDs()
+	want := []PID{"HV4613", "HV4599"}
+
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	for _, tt := range []struct {
+		pid PID
+		want bool
+	}{{"HV4612", true}, {"hv4612", false}, {"HV461", false}, {"1V4612", false}} {
+		if tt.pid.Valid() != tt.want {
+			t.Errorf("%q.Valid() = %v, want %v", tt.pid, !tt.want, tt.want)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+// Package datasheet parses the fixed width datasheets (dsdata) published by the
+// National Geodetic Survey into DataSheet structs.
+//
+// spec at https://www.ngs.noaa.gov/DATASHEET/dsdata.pdf
+//
+//	file, _ := os.Open("CA.txt")
+//	r := datasheet.NewReader(file)
+//
+//	for {
+//		sheet, err := r.Read()
+//		if err == io.EOF {
+//			break
+//		}
+//
+//		// a ParseErrors still comes with the sheet, anything else is a read error
+//		if _, ok := err.(datasheet.ParseErrors); !ok && err != nil {
+//			return err
+//		}
+//
+//		fmt.Println(sheet.Id, sheet.BasicMetadata["DESIGNATION"])
+//	}
+package datasheet
@@ -0,0 +1,72 @@
+package datasheet
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// the sheet never had a pid on the right side of the page
+	ErrMissingId = errors.New("missing pid")
+
+	// a section parser panicked on a line it could not handle
+	ErrMalformedLine = errors.New("malformed line")
+
+	// a NETWORK accuracy line did not have the six values we expect
+	ErrNetworkAccuracy = errors.New("network accuracy line does not have 6 values")
+
+	// a superseded height line did not have exactly one height
+	ErrHeight = errors.New("height line does not have exactly 1 height")
+
+	// a POSITION line did not have a DD MM SS latitude and longitude
+	ErrPosition = errors.New("position does not have a latitude and longitude")
+
+	// a primary azimuth mark line did not have a DD MM SS grid azimuth
+	ErrGridAzimuth = errors.New("primary azimuth mark does not have a grid azimuth")
+
+	// a state plane coordinates row did not have a north and east
+	ErrProjection = errors.New("projection row does not have a north and east")
+)
+
+// ParseError is a problem found on a single line of a datasheet
+type ParseError struct {
+	// pid of the sheet the line belongs to, empty if it was not read yet
+	Pid string
+
+	// line number in the input, starting at 1
+	Line int
+
+	// name of the section the page was in, ex: basicMetadataSection
+	Section string
+
+	// the underlying problem
+	Err error
+}
+
+func (e *ParseError) Error () string {
+	pid := e.Pid
+
+	if pid == "" {
+		pid = "??????"
+	}
+
+	return fmt.Sprintf("%s line %d in %s: %v", pid, e.Line, e.Section, e.Err)
+}
+
+func (e *ParseError) Unwrap () error {
+	return e.Err
+}
+
+// ParseErrors are all the problems found in one sheet
+type ParseErrors []*ParseError
+
+func (e ParseErrors) Error () string {
+	msgs := make([]string, len(e))
+
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "; ")
+}
@@ -0,0 +1,103 @@
+package datasheet
+
+import (
+	"bytes"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// the tags that start a web page, after any space and a byte order mark
+var htmlStarts = []string{"<!doctype", "<html", "<head", "<body", "<pre", "<title", "<meta", "<!--"}
+
+var (
+	tagPattern = regexp.MustCompile(`<[^>]*>`)
+	breakPattern = regexp.MustCompile(`(?i)<br\s*/?>`)
+)
+
+// StripHTML is the text of datasheets saved from the ngs website. the lines in the <pre>
+// blocks of the page, or the whole page when it has none, with the tags taken out and the
+// entities decoded. blank lines are dropped, every line of a sheet has its pid. the banner
+// and the missing page control lines are left to the Reader, it starts a sheet at either
+func StripHTML (r io.Reader) (io.Reader, error) {
+	data, err := io.ReadAll(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	page := string(data)
+	blocks := preBlocks(page)
+
+	if len(blocks) == 0 {
+		blocks = []string{page}
+	}
+
+	var out bytes.Buffer
+
+	for _, block := range blocks {
+		block = breakPattern.ReplaceAllString(block, "\n")
+		block = html.UnescapeString(tagPattern.ReplaceAllString(block, ""))
+
+		for _, line := range strings.Split(block, "\n") {
+			line = strings.TrimRight(line, "\r")
+
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+
+			out.WriteString(line)
+			out.WriteByte('\n')
+		}
+	}
+
+	return &out, nil
+}
+
+// the text between each <pre> and </pre>, ignoring case
+func preBlocks (page string) []string {
+	lower := strings.ToLower(page)
+	blocks := make([]string, 0)
+	pos := 0
+
+	for {
+		start := strings.Index(lower[pos:], "<pre")
+
+		if start < 0 {
+			return blocks
+		}
+
+		start += pos
+		open := strings.IndexByte(lower[start:], '>')
+
+		if open < 0 {
+			return blocks
+		}
+
+		open += start + 1
+		end := strings.Index(lower[open:], "</pre")
+
+		if end < 0 {
+			// a page cut off before the end still has its sheets
+			return append(blocks, page[open:])
+		}
+
+		blocks = append(blocks, page[open:open + end])
+		pos = open + end
+	}
+}
+
+// whether the first bytes are of a web page
+func isHTML (magic []byte) bool {
+	magic = bytes.TrimPrefix(magic, []byte("\xef\xbb\xbf"))
+	start := strings.ToLower(strings.TrimLeft(string(magic), " \t\r\n"))
+
+	for _, tag := range htmlStarts {
+		if strings.HasPrefix(start, tag) {
+			return true
+		}
+	}
+
+	return false
+}
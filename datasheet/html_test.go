@@ -0,0 +1,243 @@
+package datasheet
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// the sheets of the corpus file as the web retrieval shows them, the banner in place of
+// each page control line
+func webText (t *testing.T, name string) string {
+	data, err := os.ReadFile(name)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := make([]string, 0)
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "1") {
+			lines = append(lines,
+				" The NGS Data Sheet",
+				"",
+				" See file dsdata.pdf for more information about the datasheet.",
+				"",
+				" DATASHEETS - PROGRAM = datasheet95, VERSION = 8.12.5.15",
+				"",
+			)
+
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// the web text in a page, with tags in the lines and a disclaimer after
+func webPage (t *testing.T, name string) string {
+	text := html.EscapeString(webText(t, name))
+	text = strings.ReplaceAll(text, "DESIGNATION", "<b>DESIGNATION</b>")
+
+	return "<!DOCTYPE html>\n<html><head><title>NGS Data Sheet</title></head>\n<body>\n<h2>DATASHEETS</h2>\n<PRE>\n" +
+		text + "</PRE>\n<p>The data sheets are provided as is &amp; without warranty.</p>\n</body></html>\n"
+}
+
+// the corpus sheets as they are read from the web, with the program of the banner
+func webSheets (t *testing.T, name string) []DataSheet {
+	sheets := readCorpus(t, []string{name})
+
+	for i := range sheets {
+		sheets[i].Provenance = nil
+		sheets[i].Program = "datasheet95"
+		sheets[i].ProgramVersion = "8.12.5.15"
+	}
+
+	return sheets
+}
+
+func readSheets (t *testing.T, r io.Reader) []DataSheet {
+	t.Helper()
+	reader := NewReader(r)
+	sheets := make([]DataSheet, 0)
+
+	for {
+		sheet, err := reader.Read()
+
+		if err == io.EOF {
+			return sheets
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sheets = append(sheets, sheet)
+	}
+}
+
+func TestStripHTML (t *testing.T) {
+	dir := t.TempDir()
+
+	for name := range corpus {
+		want := webSheets(t, name)
+		text, err := StripHTML(strings.NewReader(webPage(t, name)))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := readSheets(t, text); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: the sheets of the page are not the ones of the file", name)
+		}
+
+		// a page saved as text has the banner too
+		if got := readSheets(t, strings.NewReader(webText(t, name))); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: the sheets of the text page are not the ones of the file", name)
+		}
+
+		// the pipeline takes the page as it is
+		path := filepath.Join(dir, filepath.Base(name) + ".html")
+
+		if err := os.WriteFile(path, []byte(webPage(t, name)), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		got := make([]DataSheet, 0)
+
+		for result := range (Pipeline{Ordered: true}).Run(context.Background(), []Input{FileInput(path)}) {
+			if result.Err != nil {
+				t.Fatal(result.Err)
+			}
+
+			got = append(got, result.Sheet)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: the pipeline got other sheets from the page", name)
+		}
+
+		// a lookup goes through the page too
+		index := indexFiles(t, []string{path})
+		sheet, err := index.LookupPID(PID(want[len(want) - 1].Id))
+
+		if err != nil || !reflect.DeepEqual(sheet, want[len(want) - 1]) {
+			t.Errorf("%s: the sheet looked up in the page is not the one in the file: %v", name, err)
+		}
+	}
+}
+
+func TestBannerLines (t *testing.T) {
+	var classifier lineClassifier
+
+	for _, banner := range []string{
+		" See file dsdata.pdf for more information about the datasheet.",
+		" See file dsdata.txt for more information about the datasheet.",
+		"   The NGS Data Sheet",
+	} {
+		if line := classifier.classify(banner); line.kind != pageLine {
+			t.Errorf("%q is not a banner", banner)
+		}
+	}
+
+	// an older page points to dsdata.txt, its program line is part of the same start
+	text := strings.Replace(webText(t, "testdata/first_order.txt"), "dsdata.pdf for more", "dsdata.txt for more", -1)
+
+	if got := readSheets(t, strings.NewReader(text)); !reflect.DeepEqual(got, webSheets(t, "testdata/first_order.txt")) {
+		t.Error("the sheets of an older page are not the ones of the file")
+	}
+}
+
+func TestStripHTMLEntities (t *testing.T) {
+	page := "<html><body><pre>\n HV4612  DESIGNATION -  A &amp; B &lt;1&gt;<br> HV4612  PID         -  HV4612\r\n\n</pre></body></html>"
+	text, err := StripHTML(strings.NewReader(page))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := io.ReadAll(text)
+
+	if want := " HV4612  DESIGNATION -  A & B <1>\n HV4612  PID         -  HV4612\n"; string(data) != want {
+		t.Errorf("got %q, want %q", data, want)
+	}
+}
+
+func TestIsHTML (t *testing.T) {
+	tests := []struct {
+		start string
+		want bool
+	}{
+		{"<!DOCTYPE html>", true},
+		{"\xef\xbb\xbf\n  <HTML>", true},
+		{"<pre>", true},
+		{"1        National Geodetic Survey", false},
+		{" HV4612 ****", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := isHTML([]byte(tt.start)); got != tt.want {
+			t.Errorf("isHTML(%q) = %v, want %v", tt.start, got, tt.want)
+		}
+	}
+
+	// a page in a bundle is read like one on its own
+	var out bytes.Buffer
+	Unpack(strings.NewReader("<html><pre>\n HV4612 ***\n</pre></html>"), func (member string, r io.Reader) error {
+		io.Copy(&out, r)
+		return nil
+	})
+
+	if out.String() != " HV4612 ***\n" {
+		t.Errorf("Unpack gave %q for a page", out.String())
+	}
+}
+
+func TestUnpackHTMLMembers (t *testing.T) {
+	name := "testdata/first_order.txt"
+	page := []byte(webPage(t, name))
+	want := webSheets(t, name)
+
+	for _, kind := range []string{"tar", "zip"} {
+		var out bytes.Buffer
+
+		if kind == "tar" {
+			tw := tar.NewWriter(&out)
+			tw.WriteHeader(&tar.Header{Name: "saved/first_order.html", Mode: 0644, Size: int64(len(page)), Typeflag: tar.TypeReg})
+			tw.Write(page)
+			tw.Close()
+		} else {
+			zw := zip.NewWriter(&out)
+			w, _ := zw.Create("saved/first_order.html")
+			w.Write(page)
+			zw.Close()
+		}
+
+		got := make([]DataSheet, 0)
+
+		err := Unpack(bytes.NewReader(out.Bytes()), func (member string, r io.Reader) error {
+			got = append(got, readSheets(t, r)...)
+			return nil
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: the sheets of the page in the bundle are not the ones of the file", kind)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package datasheet
+
+import (
+	"sort"
+)
+
+// Index holds the sheets of an archive by pid and ties them together through the pids of
+// their reference objects. the links go both ways, a station is a neighbor of the stations
+// it references and of the stations that reference it
+type Index struct {
+	sheets map[PID]*DataSheet
+
+	// the neighbors of every sheet, sorted, made when first needed and dropped on Add
+	edges map[PID][]PID
+}
+
+// DanglingReference is a reference object whose pid is not in the index
+type DanglingReference struct {
+	// the sheet the reference is on
+	From PID `json:"from"`
+
+	// the pid it points at
+	To PID `json:"to"`
+
+	// the name of the reference object, ex: MOUNT HOPE AZ MK
+	Ref string `json:"ref"`
+}
+
+func NewIndex () *Index {
+	return &Index{
+		sheets: make(map[PID]*DataSheet),
+	}
+}
+
+// Add puts the sheet in the index, a sheet with the same pid is replaced. sheets without a pid are left out
+func (index *Index) Add (sheet DataSheet) {
+	if sheet.Id == "" {
+		return
+	}
+
+	index.sheets[PID(sheet.Id)] = &sheet
+	index.edges = nil
+}
+
+// Load adds every sheet of the results, sheets with a ParseErrors are still added.
+// any other error stops the loading and is returned, cancel the context of the pipeline so it stops too
+func (index *Index) Load (results <-chan Result) error {
+	for result := range results {
+		if _, ok := result.Err.(ParseErrors); !ok && result.Err != nil {
+			return result.Err
+		}
+
+		index.Add(result.Sheet)
+	}
+
+	return nil
+}
+
+// Len is the number of sheets in the index
+func (index *Index) Len () int {
+	return len(index.sheets)
+}
+
+// Sheet is the sheet of the pid, false when it is not in the index
+func (index *Index) Sheet (pid PID) (*DataSheet, bool) {
+	sheet, ok := index.sheets[pid]
+	return sheet, ok
+}
+
+// Resolve is the sheet the reference object points at, false for references without
+// a pid, ex: reference marks, or with one that is not in the index
+func (index *Index) Resolve (ref ReferenceObject) (*DataSheet, bool) {
+	if ref.Station == "" {
+		return nil, false
+	}
+
+	return index.Sheet(ref.Station)
+}
+
+// PIDs are the pids of every sheet in the index, sorted
+func (index *Index) PIDs () []PID {
+	pids := make([]PID, 0, len(index.sheets))
+
+	for pid := range index.sheets {
+		pids = append(pids, pid)
+	}
+
+	sortPIDs(pids)
+	return pids
+}
+
+// Dangling are the references to pids that are not in the index, by the sheet they are on
+func (index *Index) Dangling () []DanglingReference {
+	dangling := make([]DanglingReference, 0)
+
+	for _, from := range index.PIDs() {
+		for _, ref := range index.sheets[from].ReferenceObjects {
+			if ref.Station == "" || ref.Station == from {
+				continue
+			}
+
+			if _, ok := index.sheets[ref.Station]; !ok {
+				dangling = append(dangling, DanglingReference{From: from, To: ref.Station, Ref: ref.Ref})
+			}
+		}
+	}
+
+	return dangling
+}
+
+// Neighbors are the stations in the index the pid references or is referenced by, sorted
+func (index *Index) Neighbors (pid PID) []PID {
+	neighbors := index.graph()[pid]
+	out := make([]PID, len(neighbors))
+	copy(out, neighbors)
+
+	return out
+}
+
+// Components are the groups of stations tied together by references, a station without any is
+// a group of its own. each group is sorted, the biggest groups come first
+func (index *Index) Components () [][]PID {
+	edges := index.graph()
+	seen := make(map[PID]bool)
+	components := make([][]PID, 0)
+
+	for _, start := range index.PIDs() {
+		if seen[start] {
+			continue
+		}
+
+		seen[start] = true
+		component := []PID{start}
+
+		// the component grows as we walk it
+		for i := 0; i < len(component); i++ {
+			for _, next := range edges[component[i]] {
+				if !seen[next] {
+					seen[next] = true
+					component = append(component, next)
+				}
+			}
+		}
+
+		sortPIDs(component)
+		components = append(components, component)
+	}
+
+	sort.SliceStable(components, func (i, j int) bool {
+		return len(components[i]) > len(components[j])
+	})
+
+	return components
+}
+
+// Path is the shortest chain of references from one station to the other, both ends included.
+// false when either is not in the index or they are not tied together
+func (index *Index) Path (from PID, to PID) ([]PID, bool) {
+	if _, ok := index.sheets[from]; !ok {
+		return nil, false
+	}
+
+	if _, ok := index.sheets[to]; !ok {
+		return nil, false
+	}
+
+	edges := index.graph()
+
+	// the station each one was first reached from
+	previous := map[PID]PID{from: from}
+	queue := []PID{from}
+
+	for len(queue) > 0 && queue[0] != to {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, next := range edges[current] {
+			if _, ok := previous[next]; !ok {
+				previous[next] = current
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	if _, ok := previous[to]; !ok {
+		return nil, false
+	}
+
+	path := []PID{to}
+
+	for path[0] != from {
+		path = append([]PID{previous[path[0]]}, path...)
+	}
+
+	return path, true
+}
+
+// the neighbors of every sheet, only links between sheets in the index are kept
+func (index *Index) graph () map[PID][]PID {
+	if index.edges != nil {
+		return index.edges
+	}
+
+	linked := make(map[PID]map[PID]bool)
+
+	link := func (a PID, b PID) {
+		if linked[a] == nil {
+			linked[a] = make(map[PID]bool)
+		}
+
+		linked[a][b] = true
+	}
+
+	for from, sheet := range index.sheets {
+		for _, to := range sheet.ReferencedPIDs() {
+			if _, ok := index.sheets[to]; ok {
+				link(from, to)
+				link(to, from)
+			}
+		}
+	}
+
+	index.edges = make(map[PID][]PID, len(linked))
+
+	for pid, neighbors := range linked {
+		for neighbor := range neighbors {
+			index.edges[pid] = append(index.edges[pid], neighbor)
+		}
+
+		sortPIDs(index.edges[pid])
+	}
+
+	return index.edges
+}
+
+func sortPIDs (pids []PID) {
+	sort.Slice(pids, func (i, j int) bool {
+		return pids[i] < pids[j]
+	})
+}
@@ -0,0 +1,150 @@
+package datasheet
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// a sheet with a reference object for each pid
+func linkedSheet (id string, refs ...string) DataSheet {
+	sheet := DataSheet{Id: id}
+
+	for _, ref := range refs {
+		sheet.ReferenceObjects = append(sheet.ReferenceObjects, ReferenceObject{Pid: ref, Ref: ref + " AZ MK", Station: PID(ref)})
+	}
+
+	return sheet
+}
+
+// AA0001 - AA0002 - AA0003 - AA0004, AA0001 - AA0004, AA0005 alone and AB0001 - AB0002
+func testIndex () *Index {
+	index := NewIndex()
+	index.Add(linkedSheet("AA0001", "AA0002", "AA0004"))
+	index.Add(linkedSheet("AA0002", "AA0003"))
+	index.Add(linkedSheet("AA0003"))
+	index.Add(linkedSheet("AA0004", "AA0003", "ZZ9999"))
+	index.Add(linkedSheet("AA0005"))
+	index.Add(linkedSheet("AB0002", "AB0001"))
+	index.Add(linkedSheet("AB0001"))
+
+	return index
+}
+
+func TestIndexNeighbors (t *testing.T) {
+	index := testIndex()
+
+	tests := []struct {
+		pid PID
+		want []PID
+	}{
+		{"AA0001", []PID{"AA0002", "AA0004"}},
+		{"AA0003", []PID{"AA0002", "AA0004"}},
+		{"AA0004", []PID{"AA0001", "AA0003"}},
+		{"AA0005", []PID{}},
+		{"ZZ9999", []PID{}},
+	}
+
+	for _, tt := range tests {
+		if got := index.Neighbors(tt.pid); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Neighbors(%s) = %v, want %v", tt.pid, got, tt.want)
+		}
+	}
+}
+
+func TestIndexDangling (t *testing.T) {
+	got := testIndex().Dangling()
+	want := []DanglingReference{{From: "AA0004", To: "ZZ9999", Ref: "ZZ9999 AZ MK"}}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestIndexComponents (t *testing.T) {
+	got := testIndex().Components()
+	want := [][]PID{
+		{"AA0001", "AA0002", "AA0003", "AA0004"},
+		{"AA0005"},
+		{"AB0001", "AB0002"},
+	}
+
+	if len(got) != 3 || !reflect.DeepEqual(got[0], want[0]) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	// groups of the same size keep the order of their first pid
+	if !reflect.DeepEqual(got[1:], [][]PID{want[2], want[1]}) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestIndexPath (t *testing.T) {
+	index := testIndex()
+
+	tests := []struct {
+		from PID
+		to PID
+		want []PID
+		ok bool
+	}{
+		{"AA0001", "AA0003", []PID{"AA0001", "AA0002", "AA0003"}, true},
+		{"AA0003", "AA0001", []PID{"AA0003", "AA0002", "AA0001"}, true},
+		{"AA0002", "AA0004", []PID{"AA0002", "AA0001", "AA0004"}, true},
+		{"AA0001", "AA0001", []PID{"AA0001"}, true},
+		{"AA0001", "AB0001", nil, false},
+		{"AA0001", "ZZ9999", nil, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := index.Path(tt.from, tt.to)
+
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Path(%s, %s) = %v %v, want %v %v", tt.from, tt.to, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestIndexLoad (t *testing.T) {
+	_, inputs := corpusInputs()
+	index := NewIndex()
+
+	if err := index.Load(Pipeline{}.Run(context.Background(), inputs)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := 0
+
+	for _, pids := range corpus {
+		want += len(pids)
+	}
+
+	if index.Len() != want {
+		t.Errorf("loaded %d sheets, want %d", index.Len(), want)
+	}
+
+	sheet, ok := index.Sheet("HV4612")
+
+	if !ok {
+		t.Fatal("HV4612 is not in the index")
+	}
+
+	// the corpus has none of the stations HV4612 references
+	if _, ok := index.Resolve(sheet.ReferenceObjects[0]); ok {
+		t.Error("resolved a pid that is not in the corpus")
+	}
+
+	if got := len(index.Dangling()); got != 2 {
+		t.Errorf("got %d dangling references, want 2", got)
+	}
+
+	index.Add(linkedSheet("HV4613"))
+
+	if resolved, ok := index.Resolve(sheet.ReferenceObjects[0]); !ok || resolved.Id != "HV4613" {
+		t.Errorf("HV4613 did not resolve after adding it")
+	}
+
+	if got, _ := index.Path("HV4613", "HV4612"); len(got) != 2 {
+		t.Errorf("got path %v, want HV4613 HV4612", got)
+	}
+}
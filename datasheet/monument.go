@@ -0,0 +1,93 @@
+package datasheet
+
+import (
+	"strings"
+)
+
+// keys of the monumentation we know how to parse
+var (
+	markerKey = "_MARKER"
+	settingKey = "_SETTING"
+	specificSettingKey = "_SP_SET"
+	stampingKey = "_STAMPING"
+	markLogoKey = "_MARK LOGO"
+	projectionKey = "_PROJECTION"
+	magneticKey = "_MAGNETIC"
+	stabilityKey = "_STABILITY"
+	satelliteKey = "_SATELLITE"
+	rodDepthKey = "_ROD/PIPE-DEPTH"
+	sleeveDepthKey = "_SLEEVE-DEPTH"
+
+	notSuitable = "NOT SUITABLE"
+)
+
+// parses the value of a monumentation item into the typed Monument of the sheet.
+// it is called again with the whole value when a + line carries the item on
+func (page *Page) monumentItem (key string, value string) {
+	switch key {
+	case markerKey:
+		page.monument().MarkerCode, page.monument().Marker = splitCode(value)
+		page.monument().MarkerType = Marker(page.monument().MarkerCode)
+	case settingKey:
+		page.monument().SettingCode, page.monument().Setting = splitCode(value)
+		page.monument().SettingType = Setting(page.monument().SettingCode)
+	case specificSettingKey: page.monument().SpecificSetting = value
+	case stampingKey: page.monument().Stamping = value
+	case markLogoKey: page.monument().MarkLogo = value
+	case projectionKey: page.monument().Projection = value
+	case magneticKey: page.monument().MagneticCode, page.monument().Magnetic = splitCode(value)
+	case stabilityKey:
+		page.monument().StabilityCode, page.monument().Stability = splitCode(value)
+		page.monument().StabilityType = Stability(page.monument().StabilityCode)
+	case satelliteKey: page.monument().Satellite = parseSatellite(value)
+	case rodDepthKey: page.monument().RodDepth = parseDepth(value)
+	case sleeveDepthKey: page.monument().SleeveDepth = parseDepth(value)
+	}
+}
+
+// the monument of the current sheet, made when the first item is found
+func (page *Page) monument () *Monument {
+	if page.CurrentSheet.Monument == nil {
+		page.CurrentSheet.Monument = &Monument{}
+	}
+
+	return page.CurrentSheet.Monument
+}
+
+// "DD = SURVEY DISK" => DD, SURVEY DISK. a value without a code is all description
+func splitCode (value string) (string, string) {
+	parts := strings.SplitN(value, " = ", 2)
+
+	if len(parts) != 2 {
+		return "", value
+	}
+
+	return trimWhiteSpace(parts[0]), trimWhiteSpace(parts[1])
+}
+
+// "THE SITE LOCATION WAS REPORTED AS SUITABLE FOR SATELLITE OBSERVATIONS - April 20, 2004"
+func parseSatellite (value string) *Satellite {
+	satellite := &Satellite{
+		Suitable: !strings.Contains(value, notSuitable),
+	}
+
+	if i := strings.LastIndex(value, " - "); i != -1 {
+		satellite.Date = trimWhiteSpace(value[i + 3:])
+	}
+
+	return satellite
+}
+
+// "6.1 meters" => 6.1 meters, nil without a number
+func parseDepth (value string) *Depth {
+	nums := getNumbersFromString(value)
+
+	if len(nums) != 1 {
+		return nil
+	}
+
+	return &Depth{
+		Value: nums[0],
+		Unit: trimWhiteSpace(strings.TrimLeft(value, "0123456789.-+ ")),
+	}
+}
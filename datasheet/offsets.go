@@ -0,0 +1,376 @@
+package datasheet
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var (
+	// the pid is not in the OffsetIndex, or the reader has no index to seek with
+	ErrNotIndexed = errors.New("pid is not indexed")
+
+	// the sheet at the offset of the index has another pid, the file changed since it was indexed
+	ErrStaleIndex = errors.New("index does not match the file")
+
+	// Reader.Seek was called on an input that can not seek to the sheet
+	ErrNotSeekable = errors.New("input can not seek")
+
+	// the columns of a sidecar, a file with other columns is not one this version wrote
+	sidecarHeader = []string{"pid", "member", "offset", "length", "line", "designation"}
+)
+
+// SidecarExt is put after the name of a file for the name of its sidecar index, ex: CA.txt.idx
+var SidecarExt = ".idx"
+
+// Location is where the page of a sheet is in a file
+type Location struct {
+	PID PID `json:"pid"`
+	Designation string `json:"designation"`
+
+	// the file as it was given to the pipeline
+	File string `json:"file"`
+
+	// the member of a bundle the sheet is in, empty for a plain file
+	Member string `json:"member,omitempty"`
+
+	// the byte offset and length of the page in the file, or in the member unpacked
+	Offset int64 `json:"offset"`
+	Length int64 `json:"length"`
+
+	// lines before the page
+	Line int `json:"line"`
+}
+
+// OffsetIndex holds where the sheets of an archive are by pid and designation, so a single
+// sheet can be parsed without reading the whole file. it is saved as a sidecar next to each
+// file, see WriteSidecars
+type OffsetIndex struct {
+	// every location added, the ones of a pid added again later too
+	locations []Location
+
+	// the position in locations of the last location of each pid
+	pids map[PID]int
+
+	// the positions in locations of each upper case designation
+	designations map[string][]int
+}
+
+func NewOffsetIndex () *OffsetIndex {
+	return &OffsetIndex{
+		pids: make(map[PID]int),
+		designations: make(map[string][]int),
+	}
+}
+
+// Add puts the location in the index, a later location with the same pid is the one looked
+// up. ones without a pid are left out
+func (index *OffsetIndex) Add (location Location) {
+	if location.PID == "" {
+		return
+	}
+
+	if i, ok := index.pids[location.PID]; ok {
+		old := normalizeDesignation(index.locations[i].Designation)
+		index.designations[old] = removeInt(index.designations[old], i)
+	}
+
+	i := len(index.locations)
+	index.locations = append(index.locations, location)
+	index.pids[location.PID] = i
+
+	designation := normalizeDesignation(location.Designation)
+	index.designations[designation] = append(index.designations[designation], i)
+}
+
+// Load adds the location of every sheet of the results, sheets with a ParseErrors are
+// still added. any other error stops the loading and is returned, cancel the context of the
+// pipeline so it stops too
+func (index *OffsetIndex) Load (results <-chan Result) error {
+	for result := range results {
+		if _, ok := result.Err.(ParseErrors); !ok && result.Err != nil {
+			return result.Err
+		}
+
+		index.Add(Location{
+			PID: PID(result.Sheet.Id),
+			Designation: result.Sheet.BasicMetadata["DESIGNATION"],
+			File: result.File,
+			Member: result.Sheet.Member,
+			Offset: result.Offset,
+			Length: result.Length,
+			Line: result.Line,
+		})
+	}
+
+	return nil
+}
+
+// Len is the number of pids in the index
+func (index *OffsetIndex) Len () int {
+	return len(index.pids)
+}
+
+// Locations is every location in the order they were added, with the ones of a pid that
+// was added again
+func (index *OffsetIndex) Locations () []Location {
+	return append([]Location(nil), index.locations...)
+}
+
+// Lookup is where the sheet with the pid is
+func (index *OffsetIndex) Lookup (pid PID) (Location, bool) {
+	i, ok := index.pids[pid]
+
+	if !ok {
+		return Location{}, false
+	}
+
+	return index.locations[i], true
+}
+
+// Designation is where the sheets with the designation are, ignoring case
+func (index *OffsetIndex) Designation (designation string) []Location {
+	out := make([]Location, 0)
+
+	for _, i := range index.designations[normalizeDesignation(designation)] {
+		out = append(out, index.locations[i])
+	}
+
+	return out
+}
+
+// LookupPID reads and parses the one sheet with the pid. a plain file is seeked to the sheet,
+// a compressed or bundled one is unpacked up to it. like Reader.Read a ParseErrors comes
+// with the sheet, ErrStaleIndex is returned when the file changed since it was indexed
+func (index *OffsetIndex) LookupPID (pid PID) (DataSheet, error) {
+	location, ok := index.Lookup(pid)
+
+	if !ok {
+		return DataSheet{}, ErrNotIndexed
+	}
+
+	file, err := os.Open(location.File)
+
+	if err != nil {
+		return DataSheet{}, err
+	}
+
+	defer file.Close()
+
+	magic := make([]byte, tarMagicOffset + len(tarMagic))
+	n, _ := file.ReadAt(magic, 0)
+
+	var sheet DataSheet
+
+	if !packed(magic[:n]) {
+		reader := NewReader(file)
+		reader.File = location.File
+		reader.Index = index
+
+		if err := reader.Seek(pid); err != nil {
+			return DataSheet{}, err
+		}
+
+		sheet, err = reader.Read()
+	} else {
+		sheet, err = readPacked(file, location)
+	}
+
+	if _, ok := err.(ParseErrors); !ok && err != nil {
+		return DataSheet{}, err
+	}
+
+	if PID(sheet.Id) != pid {
+		return DataSheet{}, fmt.Errorf("%s: %w", location.File, ErrStaleIndex)
+	}
+
+	return sheet, err
+}
+
+// a stand in error to stop unpacking once the sheet is read
+var errFound = errors.New("found")
+
+// unpacks the file up to the member of the location and reads the sheet at its offset
+func readPacked (file io.Reader, location Location) (DataSheet, error) {
+	var sheet DataSheet
+	var readErr error
+
+	err := Unpack(file, func (member string, r io.Reader) error {
+		if member != location.Member {
+			return nil
+		}
+
+		if _, err := io.CopyN(io.Discard, r, location.Offset); err != nil {
+			return err
+		}
+
+		reader := NewReader(io.LimitReader(r, location.Length))
+		reader.File = memberPath(location.File, member)
+		reader.Member = member
+		reader.LineNum = location.Line
+		reader.offsets.offset = location.Offset
+
+		sheet, readErr = reader.Read()
+		return errFound
+	})
+
+	if err == nil {
+		return DataSheet{}, ErrStaleIndex
+	}
+
+	if err != errFound {
+		return DataSheet{}, err
+	}
+
+	return sheet, readErr
+}
+
+// SidecarPath is the name of the sidecar index of the file
+func SidecarPath (path string) string {
+	return path + SidecarExt
+}
+
+// WriteSidecars writes the locations of each file to its sidecar, tab separated values
+// with a header, ex: CA.txt.idx. a file without sheets gets one with only the header
+func (index *OffsetIndex) WriteSidecars (paths []string) error {
+	byFile := make(map[string][]Location)
+
+	for _, location := range index.locations {
+		byFile[location.File] = append(byFile[location.File], location)
+	}
+
+	for _, file := range paths {
+		out, err := os.Create(SidecarPath(file))
+
+		if err != nil {
+			return err
+		}
+
+		err = writeSidecar(out, byFile[file])
+
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoadSidecars reads the sidecar index of every file
+func LoadSidecars (paths []string) (*OffsetIndex, error) {
+	index := NewOffsetIndex()
+
+	for _, path := range paths {
+		file, err := os.Open(SidecarPath(path))
+
+		if err != nil {
+			return nil, err
+		}
+
+		err = readSidecar(file, path, index)
+		file.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", SidecarPath(path), err)
+		}
+	}
+
+	return index, nil
+}
+
+func writeSidecar (w io.Writer, locations []Location) error {
+	out := csv.NewWriter(w)
+	out.Comma = '\t'
+
+	if err := out.Write(sidecarHeader); err != nil {
+		return err
+	}
+
+	for _, location := range locations {
+		err := out.Write([]string{
+			string(location.PID),
+			location.Member,
+			strconv.FormatInt(location.Offset, 10),
+			strconv.FormatInt(location.Length, 10),
+			strconv.Itoa(location.Line),
+			location.Designation,
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+// adds the locations of the sidecar of the file to the index
+func readSidecar (r io.Reader, file string, index *OffsetIndex) error {
+	in := csv.NewReader(r)
+	in.Comma = '\t'
+	in.FieldsPerRecord = len(sidecarHeader)
+
+	header, err := in.Read()
+
+	if err != nil {
+		return err
+	}
+
+	if strings.Join(header, "\t") != strings.Join(sidecarHeader, "\t") {
+		return fmt.Errorf("not a sidecar index, the header is %q", strings.Join(header, " "))
+	}
+
+	for {
+		record, err := in.Read()
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		location := Location{PID: PID(record[0]), Member: record[1], File: file, Designation: record[5]}
+		location.Offset, err = strconv.ParseInt(record[2], 10, 64)
+
+		if err == nil {
+			location.Length, err = strconv.ParseInt(record[3], 10, 64)
+		}
+
+		if err == nil {
+			location.Line, err = strconv.Atoi(record[4])
+		}
+
+		if err != nil {
+			return err
+		}
+
+		index.Add(location)
+	}
+}
+
+func normalizeDesignation (designation string) string {
+	return strings.ToUpper(strings.TrimSpace(designation))
+}
+
+func removeInt (values []int, value int) []int {
+	out := values[:0]
+
+	for _, v := range values {
+		if v != value {
+			out = append(out, v)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,167 @@
+package datasheet
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func indexFiles (t *testing.T, paths []string) *OffsetIndex {
+	t.Helper()
+	inputs := make([]Input, len(paths))
+
+	for i, path := range paths {
+		inputs[i] = FileInput(path)
+	}
+
+	index := NewOffsetIndex()
+
+	if err := index.Load((Pipeline{}).Run(context.Background(), inputs)); err != nil {
+		t.Fatal(err)
+	}
+
+	return index
+}
+
+func TestLookupPID (t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "archive.tar.gz")
+
+	if err := os.WriteFile(archive, gzipped(t, bundle(t, "tar")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the sheets of bench_marks.txt are in the tar too, the later ones win
+	index := indexFiles(t, []string{"testdata/bench_marks.txt.bz2", "testdata/first_order.txt", archive})
+	want := readCorpus(t, bundled)
+
+	if index.Len() != len(want) || len(index.Locations()) != len(want) + 3 {
+		t.Errorf("got %d sheets indexed at %d locations, want %d", index.Len(), len(index.Locations()), len(want))
+	}
+
+	for _, sheet := range want {
+		location, _ := index.Lookup(PID(sheet.Id))
+		got, err := index.LookupPID(PID(sheet.Id))
+
+		if err != nil {
+			t.Errorf("%s: %v", sheet.Id, err)
+			continue
+		}
+
+		if location.File != archive || got.Member != "DS_ARCHIVE/" + filepath.Base(sheet.Provenance["id"].File) {
+			t.Errorf("%s: read from %s %s", sheet.Id, location.File, got.Member)
+		}
+
+		got.Member = ""
+		sheet.Provenance = nil
+
+		if !reflect.DeepEqual(got, sheet) {
+			t.Errorf("%s: the sheet looked up is not the one in the file", sheet.Id)
+		}
+	}
+
+	if _, err := index.LookupPID("AA0000"); err != ErrNotIndexed {
+		t.Errorf("got %v for a pid that is not indexed", err)
+	}
+
+	if got := index.Designation(" n 35 "); len(got) != 1 || got[0].PID != "KV0001" {
+		t.Errorf("got %v for designation N 35", got)
+	}
+}
+
+func TestReaderSeek (t *testing.T) {
+	index := indexFiles(t, []string{"testdata/cors.txt", "testdata/bench_marks.txt"})
+	want := readCorpus(t, []string{"testdata/bench_marks.txt"})
+
+	file, err := os.Open("testdata/bench_marks.txt")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	reader := NewReader(file)
+	reader.Page.RecordProvenance = true
+	reader.Index = index
+
+	// back and forth, the provenance is of the whole file
+	for _, i := range []int{len(want) - 1, 0} {
+		if err := reader.Seek(PID(want[i].Id)); err != nil {
+			t.Fatal(err)
+		}
+
+		sheet, err := reader.Read()
+
+		if err != nil || !reflect.DeepEqual(sheet, want[i]) {
+			t.Errorf("%s: the sheet after seeking is not the one in the file: %v", want[i].Id, err)
+		}
+	}
+
+	// the reads go on after the sheet sought
+	if sheet, err := reader.Read(); err != nil || sheet.Id != want[1].Id {
+		t.Errorf("got %s %v after the first sheet, want %s", sheet.Id, err, want[1].Id)
+	}
+
+	cors := index.Locations()[0].PID
+
+	if err := reader.Seek(cors); !errors.Is(err, ErrNotIndexed) {
+		t.Errorf("got %v seeking to %s of another file", err, cors)
+	}
+
+	unseekable := NewReader(bytes.NewBufferString("1 DATASHEETS"))
+	unseekable.Index = index
+
+	if err := unseekable.Seek(PID(want[0].Id)); err != ErrNotSeekable {
+		t.Errorf("got %v seeking a buffer", err)
+	}
+}
+
+func TestSidecars (t *testing.T) {
+	dir := t.TempDir()
+	paths := []string{filepath.Join(dir, "cors.txt"), filepath.Join(dir, "bench_marks.txt")}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(filepath.Join("testdata", filepath.Base(path)))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		os.WriteFile(path, data, 0644)
+	}
+
+	index := indexFiles(t, paths)
+
+	if err := index.WriteSidecars(paths); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadSidecars(paths)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(loaded.Locations(), index.Locations()) {
+		t.Errorf("the sidecars have\n%v\nwant\n%v", loaded.Locations(), index.Locations())
+	}
+
+	// another release with other sheets in the file
+	data, _ := os.ReadFile(paths[0])
+	os.WriteFile(paths[1], data, 0644)
+
+	if _, err := loaded.LookupPID("KV0001"); !errors.Is(err, ErrStaleIndex) {
+		t.Errorf("got %v for a file that changed", err)
+	}
+
+	os.WriteFile(SidecarPath(paths[0]), []byte("pid\toffset\n"), 0644)
+
+	if _, err := LoadSidecars(paths); err == nil {
+		t.Errorf("a sidecar with other columns loaded")
+	}
+}
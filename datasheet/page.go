@@ -1,6 +1,8 @@
-package main
+package datasheet
 
 import (
+	"fmt"
+	"math"
 	"strconv"
 	"strings"
 )
@@ -29,7 +31,7 @@ var (
 	surveyControlEndA = ".See file dsdata.pdf to determine how the superseded data were derived."
 	surveyControlEndB = ".No superseded survey control is available for this station."
 	ellipHKey = "ELLIP H"
-	orthometricHeightKey = "NAVD"
+	verticalDatums = []string{"NAVD 88", "NGVD 29"}
 	historyKey = "HISTORY"
 	stationDescriptionHeader = "STATION DESCRIPTION"
 	stationRevoveryHeader = "STATION RECOVERY"
@@ -37,6 +39,20 @@ var (
 	accuracyHeader = "North         East    Units  Estimated Accuracy"
 	statePlaneHeader = "North         East     Units Scale Factor Converg."
 	spatialAddressKey = "U.S. NATIONAL GRID SPATIAL ADDRESS"
+
+	// names of the sections, used when reporting errors
+	sectionNames = []string{
+		"basicMetadataSection",
+		"currentSurveyControlSection",
+		"accuracySection",
+		"dataDeterminationMethodologySection",
+		"projectionsSection",
+		"azimuthMarksSection",
+		"supersededSurveyControlSection",
+		"monumentationSection",
+		"historySection",
+		"descriptionAndRecoverySection",
+	}
 )
 
 type Page struct {
@@ -44,6 +60,20 @@ type Page struct {
 	CurrentSection int
 	LineNum int
 	CurrentBuffer string
+
+	// line number of the current line in the whole input, set by the reader
+	SourceLine int
+
+	// problems found while parsing the current sheet
+	Errors ParseErrors
+
+	// when true every record of the sheet gets an entry in DataSheet.Provenance
+	RecordProvenance bool
+
+	// where the current line is in the input, set by the reader
+	SourceFile string
+	SourceOffset int64
+	SourceLength int64
 }
 
 func NewPage () Page {
@@ -58,6 +88,7 @@ func (page *Page) Reset () {
 	page.CurrentSection = basicMetadataSection
 	page.CurrentBuffer = ""
 	page.LineNum = 0
+	page.Errors = nil
 }
 
 func (page *Page) AddLine (line string) {
@@ -65,6 +96,18 @@ func (page *Page) AddLine (line string) {
 		page.ReadId(line)
 	}
 
+	page.parseLine(line)
+	page.LineNum++
+}
+
+// gives the line to the parser of the current section, a panic while parsing is kept as an error
+func (page *Page) parseLine (line string) {
+	defer func() {
+		if r := recover(); r != nil {
+			page.addError(fmt.Errorf("%w: %v", ErrMalformedLine, r))
+		}
+	}()
+
 	// we give the current line to the correct parser
 	switch page.CurrentSection {
 	case basicMetadataSection: page.BasicMetadataSection(line)
@@ -78,16 +121,40 @@ func (page *Page) AddLine (line string) {
 	case historySection: page.HistorySection(line)
 	case descriptionAndRecoverySection: page.DescriptionAndRecoverySection(line)
 	}
-
-	page.LineNum++
 }
 
 func (page *Page) Make () DataSheet {
 	defer page.Reset()
 
+	page.finish()
 	return page.CurrentSheet
 }
 
+// checks the sheet once all of its lines are in
+func (page *Page) finish () {
+	if page.CurrentSheet.Id == "" && page.LineNum > 0 {
+		page.addError(ErrMissingId)
+	}
+}
+
+// records a problem with the current line
+func (page *Page) addError (err error) {
+	page.Errors = append(page.Errors, &ParseError{
+		Pid: page.CurrentSheet.Id,
+		Line: page.SourceLine,
+		Section: sectionName(page.CurrentSection),
+		Err: err,
+	})
+}
+
+func sectionName (section int) string {
+	if section < 0 || section >= len(sectionNames) {
+		return "unknownSection"
+	}
+
+	return sectionNames[section]
+}
+
 
 // gets the first 1:7 chars of the line
 func (page *Page) ReadId (line string) {
@@ -95,7 +162,15 @@ func (page *Page) ReadId (line string) {
 		return
 	}
 
-	page.CurrentSheet.Id = line[1:7]
+	// a blank pid column is no pid
+	id := strings.TrimSpace(line[1:7])
+
+	if id == "" {
+		return
+	}
+
+	page.CurrentSheet.Id = id
+	page.mark("id")
 }
 
 func (page *Page) BasicMetadataSection (line string) {
@@ -137,6 +212,7 @@ func (page *Page) BasicMetadataSection (line string) {
 	}
 
 	page.CurrentSheet.BasicMetadata[key] = value
+	page.mark("metadata." + key)
 }
 
 func (page *Page) CurrentSurveyControlSection (line string) {
@@ -160,6 +236,19 @@ func (page *Page) CurrentSurveyControlSection (line string) {
 			}
 		}
 
+		// short current surveys like the EPOCH do not reach the by column
+		if len(line) > 31 && string(line[7]) == "*" && string(line[30]) == "-" {
+			survey := Survey{
+				Item:  trimWhiteSpace(line[9:30]),
+				Value: trimWhiteSpace(line[31:]),
+				By:    "",
+			}
+
+			page.CurrentSheet.NewSurveyControl = append(page.CurrentSheet.NewSurveyControl, survey)
+			page.markItem("newSurveys", len(page.CurrentSheet.NewSurveyControl))
+			page.surveyControl(survey)
+		}
+
 		return
 	}
 
@@ -179,6 +268,8 @@ func (page *Page) CurrentSurveyControlSection (line string) {
 		}
 
 		page.CurrentSheet.NewSurveyControl = append(page.CurrentSheet.NewSurveyControl, survey)
+		page.markItem("newSurveys", len(page.CurrentSheet.NewSurveyControl))
+		page.surveyControl(survey)
 		return
 	}
 
@@ -189,6 +280,8 @@ func (page *Page) CurrentSurveyControlSection (line string) {
 	}
 
 	page.CurrentSheet.OldSurveyControl = append(page.CurrentSheet.OldSurveyControl, survey)
+	page.markItem("oldSurveys", len(page.CurrentSheet.OldSurveyControl))
+	page.surveyControl(survey)
 
 }
 
@@ -205,14 +298,27 @@ func (page *Page) AccuracySection (line string) {
 		return
 	}
 
+	// no methodology, the projections or the sections after them start right away
+	if strings.Contains(";_:|!", line[7:8]) || (len(line) > 40 && line[33:] == surveyControlHeader) {
+		page.CurrentSection = dataDeterminationMethodologySection
+		page.DataDeterminationMethodologySection(line)
+		return
+	}
+
 	// check for the network key
 	isNetwork := line[9:16] == networkKey
 
 	// this is a network line
 	if isNetwork {
 		// make and add the network line to the array
-		data := networkLine(line[17:])
+		data, ok := networkLine(line[17:])
+
+		if !ok {
+			page.addError(ErrNetworkAccuracy)
+		}
+
 		page.CurrentSheet.Accuracy.Network = append(page.CurrentSheet.Accuracy.Network, data)
+		page.markItem("accuracy.network", len(page.CurrentSheet.Accuracy.Network))
 		return
 	}
 
@@ -234,10 +340,23 @@ func (page *Page) AccuracySection (line string) {
 
 	val := trimWhiteSpace(valParts[1][1:])
 
+	acc := &page.CurrentSheet.Accuracy
+
+	order := parseOrderClass(val)
+
 	switch key {
-	case horzOrderKey: page.CurrentSheet.Accuracy.HorzOrder = append(page.CurrentSheet.Accuracy.HorzOrder, val)
-	case ellpOrderKey: page.CurrentSheet.Accuracy.EllpOrder = append(page.CurrentSheet.Accuracy.EllpOrder, val)
-	case vertOrderKey: page.CurrentSheet.Accuracy.VertOrder = append(page.CurrentSheet.Accuracy.VertOrder, val)
+	case horzOrderKey:
+		acc.HorzOrder = append(acc.HorzOrder, val)
+		acc.Horizontal = append(acc.Horizontal, order)
+		page.markItem("accuracy.horzOrder", len(acc.HorzOrder))
+	case ellpOrderKey:
+		acc.EllpOrder = append(acc.EllpOrder, val)
+		acc.Ellipsoid = append(acc.Ellipsoid, order)
+		page.markItem("accuracy.ellpOrder", len(acc.EllpOrder))
+	case vertOrderKey:
+		acc.VertOrder = append(acc.VertOrder, val)
+		acc.Vertical = append(acc.Vertical, order)
+		page.markItem("accuracy.vertOrder", len(acc.VertOrder))
 	}
 }
 
@@ -248,6 +367,13 @@ func (page *Page) DataDeterminationMethodologySection (line string) {
 			page.ProjectionsSection(line)
 			return
 		}
+
+		// sheets without state plane coordinates go right to the spatial address, azimuths or superseded header
+		if strings.Contains("_:|!", line[7:8]) || (len(line) > 40 && line[33:] == surveyControlHeader) {
+			page.CurrentSection = projectionsSection
+			page.ProjectionsSection(line)
+			return
+		}
 	}
 
 
@@ -257,6 +383,7 @@ func (page *Page) DataDeterminationMethodologySection (line string) {
 		page.CurrentBuffer = ""
 	} else if len(line) > 9 {
 		// regular sentence
+		page.markItem("determinationMethodology", len(page.CurrentSheet.DeterminationMethodology) + 1)
 
 		if page.CurrentBuffer == "" {
 			page.CurrentBuffer = page.CurrentBuffer + line[8:]
@@ -325,20 +452,31 @@ func (page *Page) AzimuthMarksSection (line string) {
 		nums := getNumbersFromString(line[66:])
 
 		if len(nums) != 3 {
+			page.addError(ErrGridAzimuth)
 			return
 		}
 
 		mark := PrimaryAzimuthMark{
 			Mark:  name,
 			GridAz: nums,
+			Azimuth: DegreesMinutesSeconds(nums[0], nums[1], nums[2]),
+		}
+
+		// SPC CA 6 or UTM 11
+		label := strings.SplitN(trimWhiteSpace(line[8:21]), " ", 2)
+		mark.System = label[0]
+
+		if len(label) == 2 {
+			mark.Zone = trimWhiteSpace(label[1])
 		}
 
 		page.CurrentSheet.PrimaryAzimuthMarks = append(page.CurrentSheet.PrimaryAzimuthMarks, mark)
+		page.markItem("primaryAzimuthMark", len(page.CurrentSheet.PrimaryAzimuthMarks))
 	}
 
-	// check for reference object table row
-	if string(line[7]) == "|" && string(line[8]) == " " && line[9:12] != pidKey && string(line[9]) != " " {
-		pid := line[9:15]
+	// check for reference object table row, reference marks have a name but no pid
+	if string(line[7]) == "|" && string(line[8]) == " " && line[9:12] != pidKey && trimWhiteSpace(line[9:52]) != "" {
+		pid := trimWhiteSpace(line[9:15])
 		name := trimWhiteSpace(line[16:52])
 		distance := trimWhiteSpace(line[52:67])
 		geodAz := trimWhiteSpace(line[67:76])
@@ -348,9 +486,28 @@ func (page *Page) AzimuthMarksSection (line string) {
 			Ref: name,
 			Distance: distance,
 			GeodAz: geodAz,
+			Length: parseDistance(distance),
+		}
+
+		if PID(pid).Valid() {
+			reference.Station = PID(pid)
+		}
+
+		if az, ok := parsePackedDegrees(geodAz); ok {
+			reference.Azimuth = &az
+		}
+
+		// the primary azimuth mark is listed again here with its pid
+		for i := range page.CurrentSheet.PrimaryAzimuthMarks {
+			mark := &page.CurrentSheet.PrimaryAzimuthMarks[i]
+
+			if mark.Mark == name && mark.Station == "" {
+				mark.Station = reference.Station
+			}
 		}
 
 		page.CurrentSheet.ReferenceObjects = append(page.CurrentSheet.ReferenceObjects, reference)
+		page.markItem("referenceObjects", len(page.CurrentSheet.ReferenceObjects))
 	}
 
 }
@@ -391,6 +548,7 @@ func (page *Page) SupersededSurveyControlSection (line string) {
 		}
 
 		page.CurrentSheet.SurveyLatitudeLongitudes = append(page.CurrentSheet.SurveyLatitudeLongitudes, latLng)
+		page.markItem("surveyLatitudeLongitudes", len(page.CurrentSheet.SurveyLatitudeLongitudes))
 		return
 	}
 
@@ -407,6 +565,7 @@ func (page *Page) SupersededSurveyControlSection (line string) {
 
 		// make sure there is exactly one height number in the section we expect
 		if len(height) != 1 {
+			page.addError(ErrHeight)
 			return
 		}
 
@@ -420,31 +579,34 @@ func (page *Page) SupersededSurveyControlSection (line string) {
 			Unit: unit,
 			Method: method,
 			Order: order,
+			Technique: HeightMethod(trimWhiteSpace(beforePar(method))),
+			Adjustment: trimWhiteSpace(getInnerParValue(method)),
+		}
+
+		ellipH.Meters, ellipH.Feet = heightUnits(height[0], unit, line[45:63])
+
+		if accuracy, ok := numberedOrderClass(getNumbersFromString(line[78:])); ok {
+			ellipH.Accuracy = &accuracy
 		}
 
 		page.CurrentSheet.SurveyEllipsoidHeights = append(page.CurrentSheet.SurveyEllipsoidHeights, ellipH)
+		page.markItem("surveyEllipsoidHeight", len(page.CurrentSheet.SurveyEllipsoidHeights))
 
 		return
 	}
 
-	// check for Orthometric Height
-	if line[9:13] == orthometricHeightKey {
+	// check for Orthometric Height, in any of the vertical datums
+	if isVerticalDatum(line[9:16]) {
 		date := trimWhiteSpace(line[18:26])
 		numbers := getNumbersFromString(line[29:37])
 
 		// we expect there to be one height measurement used
 		if len(numbers) != 1 {
+			page.addError(ErrHeight)
 			return
 		}
 
 		unit := getInnerParValue(line[37:43])
-
-		// todo figure out what the model is because sometimes it is feet
-		model := line[43:63]
-		if model[17:18] == "(" {
-
-		}
-
 		method := trimWhiteSpace(line[64:76])
 		orders := getNumbersFromString(line[76:79])
 
@@ -454,9 +616,19 @@ func (page *Page) SupersededSurveyControlSection (line string) {
 			Method: method,
 			Order: orders,
 			Unit: unit,
+			Datum: line[9:16],
+			Model: heightModel(method, line[43:63]),
+		}
+
+		// the column after the height has it in the other unit, "  875.6         (f)"
+		navdH.Meters, navdH.Feet = heightUnits(numbers[0], unit, line[43:63])
+
+		if order, ok := numberedOrderClass(orders); ok {
+			navdH.Accuracy = &order
 		}
 
 		page.CurrentSheet.SurveyOrthometricHeights = append(page.CurrentSheet.SurveyOrthometricHeights, navdH)
+		page.markItem("surveyOrthometricHeight", len(page.CurrentSheet.SurveyOrthometricHeights))
 
 		return
 	}
@@ -488,15 +660,28 @@ func (page *Page) MonumentationSection (line string) {
 		return
 	}
 
-	// extract key values after the pid, separated by :
-	parts := strings.Split(content, ":")
+	// a _KEY line starts an item, a +KEY line carries it on
+	if content[0:1] != "_" && content[0:1] != "+" {
+		return
+	}
+
+	// the value can have colons of its own
+	parts := strings.SplitN(content, ":", 2)
 
 	if len(parts) != 2 {
 		return
 	}
 
-	// todo looks at + for appending to it
-	page.CurrentSheet.Monumentation[parts[0]] = trimWhiteSpace(parts[1])
+	key := "_" + trimWhiteSpace(parts[0][1:])
+	value := trimWhiteSpace(parts[1])
+
+	if previous, ok := page.CurrentSheet.Monumentation[key]; ok && content[0:1] == "+" && previous != "" {
+		value = previous + " " + value
+	}
+
+	page.CurrentSheet.Monumentation[key] = value
+	page.mark("monumentation." + key)
+	page.monumentItem(key, value)
 }
 
 func (page *Page) HistorySection (line string) {
@@ -542,6 +727,7 @@ func (page *Page) HistorySection (line string) {
 	}
 
 	page.CurrentSheet.History = append(page.CurrentSheet.History, history)
+	page.markItem("history", len(page.CurrentSheet.History))
 }
 
 // last section, still alive
@@ -559,6 +745,7 @@ func (page *Page) DescriptionAndRecoverySection (line string)  {
 			}
 
 			page.CurrentSheet.StationDescription = append(page.CurrentSheet.StationDescription, desc)
+			page.markItem("stationDescription", len(page.CurrentSheet.StationDescription))
 			return
 		}
 
@@ -575,6 +762,7 @@ func (page *Page) DescriptionAndRecoverySection (line string)  {
 				}
 
 				page.CurrentSheet.StationRecoveries = append(page.CurrentSheet.StationRecoveries, rec)
+				page.markItem("stationRecoveries", len(page.CurrentSheet.StationRecoveries))
 				return
 			}
 		}
@@ -601,6 +789,7 @@ func (page *Page) DescriptionAndRecoverySection (line string)  {
 		}
 
 		page.CurrentSheet.StationDescription[lastIndex].Description = lastDesc + sep + newDesc
+		page.markItem("stationDescription", len(page.CurrentSheet.StationDescription))
 
 		return
 	}
@@ -616,6 +805,7 @@ func (page *Page) DescriptionAndRecoverySection (line string)  {
 		}
 
 		page.CurrentSheet.StationRecoveries[lastIndex].Description = lastDesc + sep + newDesc
+		page.markItem("stationRecoveries", len(page.CurrentSheet.StationRecoveries))
 
 		return
 	}
@@ -627,6 +817,7 @@ func (page *Page) checkSpatialAddress (line string) bool {
 		if line[8:42] == spatialAddressKey {
 			address := line[44:]
 			page.CurrentSheet.SpatialAddress = address
+			page.mark("spatialAddress")
 			return true
 		}
 	}
@@ -638,83 +829,233 @@ func (page *Page) statePlaneCoordinates (line string) {
 	// check for header
 	if string(line[8]) == " " {
 		page.CurrentBuffer = line[28:]
-	} else if page.CurrentBuffer == statePlaneHeader {
-		nums := getNumbersFromString(line[19:])
+		return
+	}
 
-		// todo handle * number
-		if len(nums) != 6 {
-			return
+	if page.CurrentBuffer != statePlaneHeader && page.CurrentBuffer != accuracyHeader {
+		return
+	}
+
+	coords, ok := projectionRow(line)
+
+	if !ok {
+		page.addError(ErrProjection)
+		return
+	}
+
+	page.CurrentSheet.StatePlaneCoordinates = append(page.CurrentSheet.StatePlaneCoordinates, coords)
+	page.markItem("statePlaneCoordinates", len(page.CurrentSheet.StatePlaneCoordinates))
+}
+
+// parses a row of the projections, both the computed
+// "HV4612;SPC CA 6     -  6,039,844.31  6,336,005.36  sFT  0.99994478  -0 09 47.9"
+// and the scaled "EW5045;SPC CA 5     -   615,560.    1,886,710.      MT  (+/- 180 meters Scaled)".
+// a value with a * next to it is flagged, a row can stop after the units
+func projectionRow (line string) (StatePlaneCoordinates, bool) {
+	coords := StatePlaneCoordinates{
+		Converg: []float64{},
+	}
+
+	if len(line) < 23 || string(line[21]) != "-" {
+		return coords, false
+	}
+
+	// SPC CA 6 or UTM 11
+	label := strings.SplitN(trimWhiteSpace(line[8:21]), " ", 2)
+	coords.System = label[0]
+
+	if len(label) == 2 {
+		coords.Zone = trimWhiteSpace(label[1])
+	}
+
+	rest := line[22:]
+
+	// the estimated accuracy is in parentheses after the units
+	if i := strings.Index(rest, "("); i != -1 {
+		coords.Estimated = trimWhiteSpace(rest[i:])
+		coords.Accuracy = parseEstimatedAccuracy(coords.Estimated)
+		rest = rest[:i]
+	}
+
+	values := make([]string, 0)
+	flags := make([]bool, 0)
+
+	for _, token := range strings.Fields(rest) {
+		// a lone * flags the value before it
+		if token == "*" {
+			if len(flags) > 0 {
+				flags[len(flags) - 1] = true
+			}
+
+			continue
 		}
 
-		unit := getProjectionUnit(line)
+		values = append(values, strings.Trim(token, "*"))
+		flags = append(flags, strings.Contains(token, "*"))
+	}
+
+	if len(values) < 2 {
+		return coords, false
+	}
+
+	north, errN := strconv.ParseFloat(strings.Replace(values[0], ",", "", -1), 64)
+	east, errE := strconv.ParseFloat(strings.Replace(values[1], ",", "", -1), 64)
+
+	if errN != nil || errE != nil {
+		return coords, false
+	}
+
+	coords.North = north
+	coords.East = east
+
+	if len(values) > 2 {
+		coords.Units = values[2]
+		coords.NorthMeters, _ = toMeters(north, coords.Units)
+		coords.EastMeters, _ = toMeters(east, coords.Units)
+	}
 
-		coords := StatePlaneCoordinates{
-			North: nums[0],
-			East: nums[1],
-			Units: unit,
-			Scale: nums[2],
-			Factor: nums[3],
-			Converg: []float64{nums[4], nums[5]},
-			Estimated: "",
+	if len(values) > 3 {
+		if scale, err := strconv.ParseFloat(values[3], 64); err == nil {
+			coords.Scale = scale
+			coords.ScaleFlagged = flags[3]
 		}
+	}
 
-		page.CurrentSheet.StatePlaneCoordinates = append(page.CurrentSheet.StatePlaneCoordinates, coords)
-	} else if page.CurrentBuffer == accuracyHeader {
-		// then parse "EW5045;SPC CA 5     -   615,560.    1,886,710.      MT  (+/- 180 meters Scaled)"
+	// -0 09 47.9, the sign is on the degrees. they go in Factor and the minutes and seconds
+	// in Converg, as they always have
+	if len(values) > 4 {
+		dms := make([]float64, 0, 3)
 
-		unit := getProjectionUnit(line)
-		nums := getNumbersFromString(line[19:50])
+		for i, value := range values[4:] {
+			n, err := strconv.ParseFloat(value, 64)
 
-		if len(nums) != 2 {
-			return
+			if err != nil || i > 2 {
+				break
+			}
+
+			dms = append(dms, n)
+			coords.ConvergenceFlagged = coords.ConvergenceFlagged || flags[4 + i]
 		}
 
-		coords := StatePlaneCoordinates{
-			North: nums[0],
-			East: nums[1],
-			Units: unit,
-			Scale: 0,
-			Factor: 0,
-			Converg: []float64{},
-			Estimated: line[57:],
+		if len(dms) > 0 {
+			coords.Factor = dms[0]
+			coords.Converg = dms[1:]
+			coords.Convergence = signedDegrees(dms)
 		}
+	}
+
+	return coords, true
+}
+
+// "12.395 METERS" or "APPROX. 5.6 KM", nil without a number
+func parseDistance (s string) *Distance {
+	distance := &Distance{}
+	fields := strings.Fields(s)
+
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "APPROX") {
+		distance.Approximate = true
+		fields = fields[1:]
+	}
 
-		page.CurrentSheet.StatePlaneCoordinates = append(page.CurrentSheet.StatePlaneCoordinates, coords)
+	if len(fields) == 0 {
+		return nil
 	}
+
+	value, err := strconv.ParseFloat(fields[0], 64)
+
+	if err != nil {
+		return nil
+	}
+
+	distance.Value = value
+
+	if len(fields) > 1 {
+		distance.Unit = fields[1]
+		distance.Meters, _ = toMeters(value, distance.Unit)
+	}
+
+	return distance
 }
 
+// dddmmss.s => decimal degrees. the digits fill the columns from the left,
+// so reference marks given to the minute are dddmm, ex: 24716 is 247 16
+func parsePackedDegrees (s string) (float64, bool) {
+	if len(s) < 5 {
+		return 0, false
+	}
 
-// gets safe and removes whitespace
-func getProjectionUnit (line string) string {
-	if len(line) < 56 {
-		return ""
+	deg, errD := strconv.ParseFloat(s[:3], 64)
+	min, errM := strconv.ParseFloat(s[3:5], 64)
+	sec := 0.0
+	var errS error
+
+	if len(s) > 5 {
+		sec, errS = strconv.ParseFloat(s[5:], 64)
 	}
 
-	unit := line[52:55]
+	if errD != nil || errM != nil || errS != nil || min >= 60 || sec >= 60 {
+		return 0, false
+	}
 
-	if string(unit[0]) == " " {
-		unit = unit[1:]
+	return DegreesMinutesSeconds(deg, min, sec), true
+}
+
+// [-0 9 47.9] => -0.163305..., the sign of the degrees is kept even when they are 0
+func signedDegrees (dms []float64) float64 {
+	d := [3]float64{}
+	copy(d[:], dms)
+	deg := DegreesMinutesSeconds(math.Abs(d[0]), d[1], d[2])
+
+	if math.Signbit(d[0]) {
+		return -deg
 	}
 
-	return unit
+	return deg
+}
+
+// "(+/- 180 meters Scaled)" => 180 meters Scaled
+func parseEstimatedAccuracy (s string) *EstimatedAccuracy {
+	fields := strings.Fields(getInnerParValue(s))
+
+	if len(fields) > 0 && fields[0] == "+/-" {
+		fields = fields[1:]
+	}
+
+	if len(fields) < 2 {
+		return nil
+	}
+
+	value, err := strconv.ParseFloat(fields[0], 64)
+
+	if err != nil {
+		return nil
+	}
+
+	return &EstimatedAccuracy{
+		Value: value,
+		Unit: fields[1],
+		Method: strings.Join(fields[2:], " "),
+	}
 }
 
 // network line data without prefix, strip " EW4726  NETWORK"
-func networkLine (line string) NetworkAccuracy {
+func networkLine (line string) (NetworkAccuracy, bool) {
 	ntw := NetworkAccuracy{}
 	nums := getNumbersFromString(line)
 
 	// add numbers based on order
-	if len(nums) == 6 {
-		ntw.Horiz = nums[0]
-		ntw.Ellip = nums[1]
-		ntw.SDN = nums[2]
-		ntw.SDE = nums[3]
-		ntw.SDH = nums[4]
-		ntw.CorrNE = nums[5]
+	if len(nums) != 6 {
+		return ntw, false
 	}
 
-	return ntw
+	ntw.Horiz = nums[0]
+	ntw.Ellip = nums[1]
+	ntw.SDN = nums[2]
+	ntw.SDE = nums[3]
+	ntw.SDH = nums[4]
+	ntw.CorrNE = nums[5]
+
+	return ntw, true
 }
 
 func getNumbersFromString (s string) []float64 {
@@ -796,6 +1137,50 @@ func getInnerParValue (s string) string {
 	return val
 }
 
+func isVerticalDatum (s string) bool {
+	for _, datum := range verticalDatums {
+		if s == datum {
+			return true
+		}
+	}
+
+	return false
+}
+
+// the height in meters and feet, from its unit or the other unit in the column after it
+func heightUnits (height float64, unit string, other string) (float64, float64) {
+	meters, _ := toMeters(height, unit)
+	feet, _ := toFeet(height, unit)
+	otherNums := getNumbersFromString(beforePar(other))
+
+	if len(otherNums) != 1 {
+		return meters, feet
+	}
+
+	switch getInnerParValue(other) {
+	case "m": meters = otherNums[0]
+	case "f": feet = otherNums[0]
+	}
+
+	return meters, feet
+}
+
+// a geoid or conversion model in the method or the column after the height, ex: VERTCON
+func heightModel (method string, other string) string {
+	if strings.HasPrefix(method, "VERTCON") || strings.HasPrefix(method, "GEOID") {
+		return method
+	}
+
+	// text that is not a height in the other unit
+	other = trimWhiteSpace(other)
+
+	if other != "" && len(getNumbersFromString(beforePar(other))) == 0 {
+		return other
+	}
+
+	return ""
+}
+
 func min (x int, y int) int {
 	if x < y {
 		return x
@@ -803,3 +1188,14 @@ func min (x int, y int) int {
 
 	return y
 }
+
+
+// ParseNumbers pulls every whitespace separated number out of s, "34 03 22.84 (N)" => [34 3 22.84]
+func ParseNumbers (s string) []float64 {
+	return getNumbersFromString(s)
+}
+
+// DegreesMinutesSeconds converts degrees, minutes and seconds to decimal degrees
+func DegreesMinutesSeconds (deg float64, min float64, sec float64) float64 {
+	return deg + (min / 60) + (sec / 3600)
+}
@@ -0,0 +1,771 @@
+package datasheet
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+type sectionTest struct {
+	name string
+
+	// lines given to the page, one after another
+	lines []string
+
+	// the section the page should be in after the lines
+	wantSection int
+
+	// checks what was parsed into the sheet
+	check func (t *testing.T, sheet DataSheet)
+}
+
+// runs each test on a fresh page that starts in the given section
+func runSectionTests (t *testing.T, section int, tests []sectionTest) {
+	t.Helper()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func (t *testing.T) {
+			page := NewPage()
+			page.CurrentSection = section
+
+			// past the first line so the id is not read again
+			page.LineNum = 1
+
+			for _, line := range tt.lines {
+				page.AddLine(line)
+			}
+
+			if page.CurrentSection != tt.wantSection {
+				t.Errorf("ended in %s, want %s", sectionName(page.CurrentSection), sectionName(tt.wantSection))
+			}
+
+			if len(page.Errors) > 0 {
+				t.Errorf("unexpected errors: %v", page.Errors)
+			}
+
+			if tt.check != nil {
+				tt.check(t, page.CurrentSheet)
+			}
+		})
+	}
+}
+
+func deepEqual (t *testing.T, got interface{}, want interface{}) {
+	t.Helper()
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestReadId (t *testing.T) {
+	page := NewPage()
+	page.AddLine(" HV4612  DESIGNATION -  MOUNT HOPE")
+
+	if page.CurrentSheet.Id != "HV4612" {
+		t.Errorf("got %q, want HV4612", page.CurrentSheet.Id)
+	}
+
+	page = NewPage()
+	page.AddLine(" HV4612")
+
+	if page.CurrentSheet.Id != "" {
+		t.Errorf("got %q from a line without content", page.CurrentSheet.Id)
+	}
+}
+
+func TestBasicMetadataSection (t *testing.T) {
+	runSectionTests(t, basicMetadataSection, []sectionTest{
+		{
+			name: "key value",
+			lines: []string{
+				" HV4612  DESIGNATION -  MOUNT HOPE",
+				" HV4612  STATE/COUNTY-  CA/SAN DIEGO",
+				" HV4612  USGS QUAD   -  LA MESA (1994)",
+			},
+			wantSection: basicMetadataSection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, sheet.BasicMetadata, map[string]string{
+					"DESIGNATION": "MOUNT HOPE",
+					"STATE/COUNTY": "CA/SAN DIEGO",
+					"USGS QUAD": "LA MESA (1994)",
+				})
+			},
+		},
+		{
+			name: "value with a dash",
+			lines: []string{" DF4370  CBN         -  This is a Cooperative Base Network Control Station."},
+			wantSection: basicMetadataSection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, sheet.BasicMetadata["CBN"], "This is a Cooperative Base Network Control Station.")
+			},
+		},
+		{
+			name: "blank line ends the section",
+			lines: []string{" HV4612"},
+			wantSection: currentSurveyControlSection,
+		},
+		{
+			name: "short line is skipped",
+			lines: []string{" HV4612  A"},
+			wantSection: basicMetadataSection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, len(sheet.BasicMetadata), 0)
+			},
+		},
+	})
+}
+
+func TestCurrentSurveyControlSection (t *testing.T) {
+	runSectionTests(t, currentSurveyControlSection, []sectionTest{
+		{
+			name: "headers are skipped",
+			lines: []string{
+				" HV4612                          *CURRENT SURVEY CONTROL",
+				" HV4612  ______________________________________________________________________",
+			},
+			wantSection: currentSurveyControlSection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, len(sheet.NewSurveyControl) + len(sheet.OldSurveyControl), 0)
+			},
+		},
+		{
+			name: "current survey",
+			lines: []string{" HV4612* NAD 83(2011) POSITION- 32 43 05.51283(N) 117 08 20.13749(W)   ADJUSTED"},
+			wantSection: currentSurveyControlSection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, sheet.NewSurveyControl, []Survey{{
+					Item: "NAD 83(2011) POSITION",
+					Value: "32 43 05.51283(N) 117 08 20.13749(W)",
+					By: "ADJUSTED",
+				}})
+			},
+		},
+		{
+			name: "short current survey",
+			lines: []string{" HV4612* NAD 83(2011) EPOCH   -  2010.00"},
+			wantSection: currentSurveyControlSection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, sheet.NewSurveyControl, []Survey{{Item: "NAD 83(2011) EPOCH", Value: "2010.00"}})
+			},
+		},
+		{
+			name: "computed survey",
+			lines: []string{" HV4612  GEOID HEIGHT    -        -34.37  (meters)                     GEOID18"},
+			wantSection: currentSurveyControlSection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, sheet.OldSurveyControl, []Survey{{Item: "GEOID HEIGHT", Value: "-34.37  (meters)", By: "GEOID18"}})
+			},
+		},
+		{
+			name: "blank line ends the section",
+			lines: []string{" HV4612"},
+			wantSection: accuracySection,
+		},
+		{
+			name: "accuracy right after the survey control",
+			lines: []string{" HV4612  HORZ ORDER      -  FIRST"},
+			wantSection: accuracySection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, sheet.Accuracy.HorzOrder, []string{"FIRST"})
+			},
+		},
+	})
+}
+
+func TestParseLatLon (t *testing.T) {
+	tests := []struct {
+		value string
+		lat float64
+		lon float64
+		ok bool
+	}{
+		{"32 43 05.51283(N) 117 08 20.13749(W)", 32.718198, -117.138927, true},
+		{"39 02 46.    (N) 095 40 40.    (W)", 39.046111, -95.677778, true},
+		{"33 51 12.00000(S) 151 12 36.00000(E)", -33.853333, 151.21, true},
+		{"32 4X 05.51283(N) 117 08 20.13749(W)", 0, 0, false},
+		{"32 60 05.51283(N) 117 08 20.13749(W)", 0, 0, false},
+		{"32 43 60.00000(N) 117 08 20.13749(W)", 0, 0, false},
+		{"91 00 00.00000(N) 117 08 20.13749(W)", 0, 0, false},
+		{"32 43 05.51283(N) 181 08 20.13749(W)", 0, 0, false},
+		{"32 43 05.51283(N) 117 08", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		lat, lon, ok := parseLatLon(tt.value)
+
+		if ok != tt.ok || math.Abs(lat - tt.lat) > 1e-6 || math.Abs(lon - tt.lon) > 1e-6 {
+			t.Errorf("parseLatLon(%q) = %v, %v, %v, want %v, %v, %v", tt.value, lat, lon, ok, tt.lat, tt.lon, tt.ok)
+		}
+	}
+}
+
+func TestBadPosition (t *testing.T) {
+	page := NewPage()
+	page.CurrentSection = currentSurveyControlSection
+	page.LineNum = 1
+	page.RecordProvenance = true
+	page.AddLine(" HV4612* NAD 83(2011) POSITION- 32 4X 05.51283(N) 117 08 20.13749(W)   ADJUSTED")
+	page.AddLine(" HV4612* NAD 83(2011) EPOCH   -  2010.00")
+
+	if page.CurrentSheet.Position != nil {
+		t.Errorf("got %+v for a position that does not parse", page.CurrentSheet.Position)
+	}
+
+	if _, ok := page.CurrentSheet.Provenance["position"]; ok {
+		t.Error("provenance was recorded for a position that does not parse")
+	}
+
+	if len(page.Errors) != 1 || page.Errors[0].Err != ErrPosition {
+		t.Errorf("got %v, want one ErrPosition", page.Errors)
+	}
+}
+
+func TestAccuracySection (t *testing.T) {
+	runSectionTests(t, accuracySection, []sectionTest{
+		{
+			name: "orders",
+			lines: []string{
+				" HV4612  HORZ ORDER      -  FIRST",
+				" HV4612  ELLP ORDER      -  FOURTH    CLASS II",
+				" KV0001  VERT ORDER      -  FIRST     CLASS II",
+			},
+			wantSection: accuracySection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, sheet.Accuracy.HorzOrder, []string{"FIRST"})
+				deepEqual(t, sheet.Accuracy.EllpOrder, []string{"FOURTH    CLASS II"})
+				deepEqual(t, sheet.Accuracy.VertOrder, []string{"FIRST     CLASS II"})
+			},
+		},
+		{
+			name: "network",
+			lines: []string{
+				" HV4612  FGDC (95% conf, cm)     Standard deviation (cm)     CorrNE",
+				" HV4612           Horiz  Ellip   SD_N   SD_E   SD_h          (unitless)",
+				" HV4612  -------------------------------------------------------------------",
+				" HV4612  NETWORK   1.41   2.67   0.61   0.58   1.36     -0.01987546",
+			},
+			wantSection: accuracySection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, sheet.Accuracy.Network, []NetworkAccuracy{{
+					Horiz: 1.41,
+					Ellip: 2.67,
+					SDN: 0.61,
+					SDE: 0.58,
+					SDH: 1.36,
+					CorrNE: -0.01987546,
+				}})
+			},
+		},
+		{
+			name: "methodology starts",
+			lines: []string{" HV4612.The horizontal coordinates were established by GPS observations"},
+			wantSection: dataDeterminationMethodologySection,
+		},
+	})
+}
+
+func TestDataDeterminationMethodologySection (t *testing.T) {
+	runSectionTests(t, dataDeterminationMethodologySection, []sectionTest{
+		{
+			name: "paragraphs",
+			lines: []string{
+				" HV4612.The horizontal coordinates were established by GPS observations",
+				" HV4612.and adjusted by the National Geodetic Survey in June 2012.",
+				" HV4612",
+				" HV4612.NAD 83(2011) refers to NAD 83 coordinates.",
+				" HV4612",
+			},
+			wantSection: dataDeterminationMethodologySection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, sheet.DeterminationMethodology, []string{
+					"The horizontal coordinates were established by GPS observations and adjusted by the National Geodetic Survey in June 2012.",
+					"NAD 83(2011) refers to NAD 83 coordinates.",
+				})
+			},
+		},
+		{
+			name: "state plane coordinates start",
+			lines: []string{" HV4612;                    North         East     Units Scale Factor Converg."},
+			wantSection: projectionsSection,
+		},
+		{
+			name: "no state plane coordinates",
+			lines: []string{" KV0002_U.S. NATIONAL GRID SPATIAL ADDRESS: 15SUD3845623052(NAD 83)"},
+			wantSection: projectionsSection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, sheet.SpatialAddress, "15SUD3845623052(NAD 83)")
+				deepEqual(t, len(sheet.DeterminationMethodology), 0)
+			},
+		},
+		{
+			name: "no projections before the azimuth marks",
+			lines: []string{" HV4612:                 Primary Azimuth Mark                      Grid Az"},
+			wantSection: azimuthMarksSection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, len(sheet.DeterminationMethodology), 0)
+			},
+		},
+		{
+			name: "no projections before the reference objects",
+			lines: []string{" HV4612|---------------------------------------------------------------------|"},
+			wantSection: azimuthMarksSection,
+		},
+		{
+			name: "no projections before the superseded survey control",
+			lines: []string{" KV0001                          SUPERSEDED SURVEY CONTROL"},
+			wantSection: supersededSurveyControlSection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, len(sheet.DeterminationMethodology), 0)
+			},
+		},
+	})
+}
+
+func TestProjectionsSection (t *testing.T) {
+	runSectionTests(t, projectionsSection, []sectionTest{
+		{
+			name: "state plane coordinates",
+			lines: []string{
+				" HV4612;                    North         East     Units Scale Factor Converg.",
+				" HV4612;SPC CA 6     -  6,039,844.31  6,336,005.36  sFT  0.99994478  -0 09 47.9",
+			},
+			wantSection: projectionsSection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, sheet.StatePlaneCoordinates, []StatePlaneCoordinates{{
+					North: 6039844.31,
+					East: 6336005.36,
+					Units: "sFT",
+					Scale: 0.99994478,
+					Factor: math.Copysign(0, -1),
+					Converg: []float64{9, 47.9},
+					System: "SPC",
+					Zone: "CA 6",
+					Convergence: -DegreesMinutesSeconds(0, 9, 47.9),
+					NorthMeters: 6039844.31 * surveyFoot,
+					EastMeters: 6336005.36 * surveyFoot,
+				}})
+			},
+		},
+		{
+			name: "utm and flagged rows",
+			lines: []string{
+				" DF4370;                    North         East     Units Scale Factor Converg.",
+				" DF4370;UTM 15       - 4,575,183.022   254,433.389   MT  1.00017231* -2 10 52.1",
+				" DF4370;SPC NE       -   163,394.     822,380.      iFT 0.99994478   +1 05 22 *",
+				" DF4370;SPC NE       -   163,394.     822,380.      MT",
+			},
+			wantSection: projectionsSection,
+			check: func (t *testing.T, sheet DataSheet) {
+				spcs := sheet.StatePlaneCoordinates
+
+				if len(spcs) != 3 {
+					t.Fatalf("got %d rows, want 3", len(spcs))
+				}
+
+				deepEqual(t, []string{spcs[0].System, spcs[0].Zone, spcs[1].Zone}, []string{"UTM", "15", "NE"})
+				deepEqual(t, []bool{spcs[0].ScaleFlagged, spcs[0].ConvergenceFlagged}, []bool{true, false})
+				deepEqual(t, []bool{spcs[1].ScaleFlagged, spcs[1].ConvergenceFlagged}, []bool{false, true})
+				deepEqual(t, []float64{spcs[0].Factor, spcs[0].Converg[0], spcs[0].Converg[1]}, []float64{-2, 10, 52.1})
+				deepEqual(t, spcs[1].Convergence, DegreesMinutesSeconds(1, 5, 22))
+				deepEqual(t, spcs[1].NorthMeters, 163394 * internationalFoot)
+				deepEqual(t, []float64{spcs[2].North, spcs[2].Scale, spcs[2].NorthMeters}, []float64{163394, 0, 163394})
+			},
+		},
+		{
+			name: "scaled state plane coordinates",
+			lines: []string{
+				" KV0001;                    North         East    Units  Estimated Accuracy",
+				" KV0001;SPC KS N     -       76,370.      682,145.   MT  (+/- 180 meters Scaled)",
+			},
+			wantSection: projectionsSection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, sheet.StatePlaneCoordinates, []StatePlaneCoordinates{{
+					North: 76370,
+					East: 682145,
+					Units: "MT",
+					Converg: []float64{},
+					Estimated: "(+/- 180 meters Scaled)",
+					System: "SPC",
+					Zone: "KS N",
+					NorthMeters: 76370,
+					EastMeters: 682145,
+					Accuracy: &EstimatedAccuracy{Value: 180, Unit: "meters", Method: "Scaled"},
+				}})
+			},
+		},
+		{
+			name: "spatial address",
+			lines: []string{" HV4612_U.S. NATIONAL GRID SPATIAL ADDRESS: 11SMS8703025992(NAD 83)"},
+			wantSection: projectionsSection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, sheet.SpatialAddress, "11SMS8703025992(NAD 83)")
+			},
+		},
+		{
+			name: "azimuth marks start",
+			lines: []string{" HV4612:                 Primary Azimuth Mark                      Grid Az"},
+			wantSection: azimuthMarksSection,
+		},
+		{
+			name: "superseded header",
+			lines: []string{" HV4612                          SUPERSEDED SURVEY CONTROL"},
+			wantSection: supersededSurveyControlSection,
+		},
+	})
+}
+
+func TestAzimuthMarksSection (t *testing.T) {
+	runSectionTests(t, azimuthMarksSection, []sectionTest{
+		{
+			name: "primary azimuth mark",
+			lines: []string{
+				" HV4612:                 Primary Azimuth Mark                      Grid Az",
+				" HV4612:SPC CA 6     -   MOUNT HOPE AZ MK                         178 31 17.6",
+			},
+			wantSection: azimuthMarksSection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, sheet.PrimaryAzimuthMarks, []PrimaryAzimuthMark{{
+					Mark: "MOUNT HOPE AZ MK",
+					GridAz: []float64{178, 31, 17.6},
+					System: "SPC",
+					Zone: "CA 6",
+					Azimuth: DegreesMinutesSeconds(178, 31, 17.6),
+				}})
+			},
+		},
+		{
+			name: "reference objects",
+			lines: []string{
+				" HV4612|---------------------------------------------------------------------|",
+				" HV4612| PID    Reference Object                     Distance      Geod. Az  |",
+				" HV4612|                                                           dddmmss.s |",
+				" HV4612| HV4599 SAN DIEGO CITY HALL FLAGPOLE        APPROX. 5.6 KM 3025523.4 |",
+				" HV4612|        MOUNT HOPE RM 1                     12.395 METERS  24716     |",
+			},
+			wantSection: azimuthMarksSection,
+			check: func (t *testing.T, sheet DataSheet) {
+				flagpoleAz := DegreesMinutesSeconds(302, 55, 23.4)
+				rmAz := DegreesMinutesSeconds(247, 16, 0)
+
+				deepEqual(t, sheet.ReferenceObjects, []ReferenceObject{
+					{
+						Pid: "HV4599",
+						Ref: "SAN DIEGO CITY HALL FLAGPOLE",
+						Distance: "APPROX. 5.6 KM",
+						GeodAz: "3025523.4",
+						Station: "HV4599",
+						Length: &Distance{Value: 5.6, Unit: "KM", Approximate: true, Meters: 5600},
+						Azimuth: &flagpoleAz,
+					},
+					{
+						Ref: "MOUNT HOPE RM 1",
+						Distance: "12.395 METERS",
+						GeodAz: "24716",
+						Length: &Distance{Value: 12.395, Unit: "METERS", Meters: 12.395},
+						Azimuth: &rmAz,
+					},
+				})
+			},
+		},
+		{
+			name: "azimuth mark linked to its pid",
+			lines: []string{
+				" HV4612:SPC CA 6     -   MOUNT HOPE AZ MK                         178 31 17.6",
+				" HV4612| HV4613 MOUNT HOPE AZ MK                                   1782129.6 |",
+			},
+			wantSection: azimuthMarksSection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, sheet.PrimaryAzimuthMarks[0].Station, PID("HV4613"))
+				deepEqual(t, sheet.ReferenceObjects[0].Length, (*Distance)(nil))
+			},
+		},
+		{
+			name: "superseded header",
+			lines: []string{" HV4612                          SUPERSEDED SURVEY CONTROL"},
+			wantSection: supersededSurveyControlSection,
+		},
+	})
+}
+
+func TestSupersededSurveyControlSection (t *testing.T) {
+	runSectionTests(t, supersededSurveyControlSection, []sectionTest{
+		{
+			name: "latitude longitude",
+			lines: []string{" HV4612  NAD 83(2007)-  32 43 05.51291(N)    117 08 20.13720(W) AD(2007.00) 0"},
+			wantSection: supersededSurveyControlSection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, sheet.SurveyLatitudeLongitudes, []SurveyLatitudeLongitude{{
+					Name: "NAD 83(2007)",
+					Pos: "32 43 05.51291(N)",
+					Body: "117 08 20.13720(W) AD(2007.00)",
+					Order: "0",
+				}})
+			},
+		},
+		{
+			name: "ellipsoid height",
+			lines: []string{" HV4612  ELLIP H (02/10/07)   97.650 (m)                        GP(2007.00)   4 1"},
+			wantSection: supersededSurveyControlSection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, sheet.SurveyEllipsoidHeights, []SurveyEllipsoidHeight{{
+					Date: "02/10/07",
+					Height: 97.65,
+					Unit: "m",
+					Method: "GP(2007.00)   ",
+					Order: "4",
+					Technique: HeightMethodGPS,
+					Adjustment: "2007.00",
+					Accuracy: &OrderClass{Order: OrderFourth, Class: "I"},
+					Meters: 97.65,
+					Feet: 97.65 * 3937 / 1200,
+				}})
+			},
+		},
+		{
+			name: "orthometric height",
+			lines: []string{" KV0001  NAVD 88 (09/30/91)  266.87  (m)      875.6         (f) ADJ UNCH    1 2"},
+			wantSection: supersededSurveyControlSection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, sheet.SurveyOrthometricHeights, []SurveyOrthometricHeight{{
+					Date: "09/30/91",
+					Height: 266.87,
+					Unit: "m",
+					Method: "ADJ UNCH",
+					Order: []float64{1, 2},
+					Accuracy: &OrderClass{Order: OrderFirst, Class: "II"},
+					Datum: "NAVD 88",
+					Meters: 266.87,
+					Feet: 875.6,
+				}})
+			},
+		},
+		{
+			name: "superseded datum",
+			lines: []string{" KV0002  NGVD 29 (??/??/92)  280.18  (m)      919.2         (f) ADJUSTED    2 0"},
+			wantSection: supersededSurveyControlSection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, sheet.SurveyOrthometricHeights, []SurveyOrthometricHeight{{
+					Date: "??/??/92",
+					Height: 280.18,
+					Unit: "m",
+					Method: "ADJUSTED",
+					Order: []float64{2, 0},
+					Accuracy: &OrderClass{Order: OrderSecond, Class: "0"},
+					Datum: "NGVD 29",
+					Meters: 280.18,
+					Feet: 919.2,
+				}})
+			},
+		},
+		{
+			name: "height in feet converted with a model",
+			lines: []string{" AB1234  NAVD 88 (06/15/91)  35.     (f)                          VERTCON     3"},
+			wantSection: supersededSurveyControlSection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, sheet.SurveyOrthometricHeights, []SurveyOrthometricHeight{{
+					Date: "06/15/91",
+					Height: 35,
+					Unit: "f",
+					Method: "VERTCON",
+					Order: []float64{3},
+					Accuracy: &OrderClass{Order: OrderThird},
+					Datum: "NAVD 88",
+					Model: "VERTCON",
+					Meters: 35 * 1200.0 / 3937,
+					Feet: 35,
+				}})
+			},
+		},
+		{
+			name: "end of superseded data",
+			lines: []string{" HV4612.See file dsdata.pdf to determine how the superseded data were derived."},
+			wantSection: monumentationSection,
+		},
+		{
+			name: "no superseded data",
+			lines: []string{" DF4370.No superseded survey control is available for this station."},
+			wantSection: monumentationSection,
+		},
+	})
+}
+
+func TestMonumentationSection (t *testing.T) {
+	runSectionTests(t, monumentationSection, []sectionTest{
+		{
+			name: "key value",
+			lines: []string{
+				" HV4612_MARKER: DD = SURVEY DISK",
+				" HV4612_SETTING: 7 = SET IN TOP OF CONCRETE MONUMENT",
+			},
+			wantSection: monumentationSection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, sheet.Monumentation, map[string]string{
+					"_MARKER": "DD = SURVEY DISK",
+					"_SETTING": "7 = SET IN TOP OF CONCRETE MONUMENT",
+				})
+			},
+		},
+		{
+			name: "continuation lines",
+			lines: []string{
+				" HV4612_STABILITY: C = MAY HOLD, BUT OF TYPE COMMONLY SUBJECT TO",
+				" HV4612+STABILITY: SURFACE MOTION",
+				" HV4612_SATELLITE: THE SITE LOCATION WAS REPORTED AS SUITABLE FOR",
+				" HV4612+SATELLITE: SATELLITE OBSERVATIONS - March 12, 2008",
+			},
+			wantSection: monumentationSection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, sheet.Monumentation, map[string]string{
+					"_STABILITY": "C = MAY HOLD, BUT OF TYPE COMMONLY SUBJECT TO SURFACE MOTION",
+					"_SATELLITE": "THE SITE LOCATION WAS REPORTED AS SUITABLE FOR SATELLITE OBSERVATIONS - March 12, 2008",
+				})
+				deepEqual(t, sheet.Monument, &Monument{
+					StabilityCode: "C",
+					Stability: "MAY HOLD, BUT OF TYPE COMMONLY SUBJECT TO SURFACE MOTION",
+					StabilityType: StabilityMayHold,
+					Satellite: &Satellite{Suitable: true, Date: "March 12, 2008"},
+				})
+			},
+		},
+		{
+			name: "colons in the value and padded keys",
+			lines: []string{
+				" AB1234_STAMPING: STA 12:30 1961",
+				" AB1234_ROD/PIPE-DEPTH: 6.1 meters",
+				" AB1234_SLEEVE-DEPTH   : 0.9 meters",
+				" AB1234_SATELLITE: THE SITE LOCATION WAS REPORTED AS NOT SUITABLE FOR",
+				" AB1234+SATELLITE: SATELLITE OBSERVATIONS - May 04, 1998",
+			},
+			wantSection: monumentationSection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, sheet.Monumentation["_STAMPING"], "STA 12:30 1961")
+				deepEqual(t, sheet.Monument, &Monument{
+					Stamping: "STA 12:30 1961",
+					Satellite: &Satellite{Suitable: false, Date: "May 04, 1998"},
+					RodDepth: &Depth{Value: 6.1, Unit: "meters"},
+					SleeveDepth: &Depth{Value: 0.9, Unit: "meters"},
+				})
+			},
+		},
+		{
+			name: "history starts",
+			lines: []string{" HV4612  HISTORY     - Date     Condition        Report By"},
+			wantSection: historySection,
+		},
+	})
+}
+
+func TestHistorySection (t *testing.T) {
+	runSectionTests(t, historySection, []sectionTest{
+		{
+			name: "rows",
+			lines: []string{
+				" HV4612  HISTORY     - Date     Condition        Report By",
+				" HV4612  HISTORY     - 1934     MONUMENTED       CGS",
+				" KV0002  HISTORY     - 20120812 DESTROYED        KSDOT",
+			},
+			wantSection: historySection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, sheet.History, []History{
+					{Date: "1934", Condition: "MONUMENTED", By: "CGS"},
+					{Date: "20120812", Condition: "DESTROYED", By: "KSDOT"},
+				})
+			},
+		},
+		{
+			name: "description starts",
+			lines: []string{" HV4612                          STATION DESCRIPTION"},
+			wantSection: descriptionAndRecoverySection,
+		},
+	})
+}
+
+func TestDescriptionAndRecoverySection (t *testing.T) {
+	runSectionTests(t, descriptionAndRecoverySection, []sectionTest{
+		{
+			name: "description and recoveries",
+			lines: []string{
+				" HV4612                          STATION DESCRIPTION",
+				" HV4612",
+				" HV4612'DESCRIBED BY COAST AND GEODETIC SURVEY 1934",
+				" HV4612'STATION IS ON THE HIGHEST POINT OF MOUNT HOPE.",
+				" HV4612",
+				" HV4612                          STATION RECOVERY (1972)",
+				" HV4612",
+				" HV4612'RECOVERED IN GOOD CONDITION.",
+			},
+			wantSection: descriptionAndRecoverySection,
+			check: func (t *testing.T, sheet DataSheet) {
+				deepEqual(t, sheet.StationDescription, []StationDescription{
+					{Description: "DESCRIBED BY COAST AND GEODETIC SURVEY 1934 STATION IS ON THE HIGHEST POINT OF MOUNT HOPE."},
+				})
+				deepEqual(t, sheet.StationRecoveries, []StationRecovery{
+					{Date: "1972", Description: "RECOVERED IN GOOD CONDITION."},
+				})
+			},
+		},
+	})
+}
+
+func TestGetNumbersFromString (t *testing.T) {
+	tests := []struct {
+		in string
+		want []float64
+	}{
+		{"", []float64{}},
+		{"32 43 05.51283(N) 117 08 20.13749(W)", []float64{32, 43, 5.51283, 117, 8, 20.13749}},
+		{"-4,795,361.519 (meters)", []float64{-4795361.519}},
+		{"  1.41   2.67   0.61   0.58   1.36     -0.01987546", []float64{1.41, 2.67, 0.61, 0.58, 1.36, -0.01987546}},
+		{"-  6,039,844.31", []float64{6039844.31}},
+		{"+0 49\t37.6", []float64{0, 49, 37.6}},
+		{"no numbers here", []float64{}},
+	}
+
+	for _, tt := range tests {
+		got := getNumbersFromString(tt.in)
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("getNumbersFromString(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTrimWhiteSpace (t *testing.T) {
+	tests := []struct {
+		in string
+		want string
+	}{
+		{"", ""},
+		{"   ", ""},
+		{"  MOUNT HOPE  ", "MOUNT HOPE"},
+		{"FOURTH    CLASS II   ", "FOURTH    CLASS II"},
+		{"GP(2007.00)", "GP(2007.00)"},
+	}
+
+	for _, tt := range tests {
+		if got := trimWhiteSpace(tt.in); got != tt.want {
+			t.Errorf("trimWhiteSpace(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGetInnerParValue (t *testing.T) {
+	tests := []struct {
+		in string
+		want string
+	}{
+		{"", ""},
+		{"(meters)", "meters"},
+		{"97.632 (meters)        (06/27/12)", "meters"},
+		{"STATION RECOVERY (1972)", "1972"},
+		{"no parentheses", ""},
+		{"(not closed", "not closed"},
+	}
+
+	for _, tt := range tests {
+		if got := getInnerParValue(tt.in); got != tt.want {
+			t.Errorf("getInnerParValue(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
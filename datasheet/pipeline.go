@@ -0,0 +1,312 @@
+package datasheet
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Input is a named source of sheets for a Pipeline
+type Input struct {
+	// used for provenance and to tell the results apart
+	Name string
+
+	// opens the input, it is closed once all of its pages are split off
+	Open func () (io.ReadCloser, error)
+}
+
+// FileInput is an Input that opens the file at path
+func FileInput (path string) Input {
+	return Input{
+		Name: path,
+		Open: func () (io.ReadCloser, error) {
+			return os.Open(path)
+		},
+	}
+}
+
+// Result is a sheet parsed by a Pipeline
+type Result struct {
+	Sheet DataSheet
+
+	// name of the input the sheet is from
+	File string
+
+	// where the page of the sheet is in the input, or in the member of a bundled one.
+	// the byte offset and length of its lines and the lines before it, see OffsetIndex
+	Offset int64
+	Length int64
+	Line int
+
+	// like Reader.Read, a ParseErrors when the sheet had problems. any other error is
+	// from opening or reading the input, the sheet is empty and the input is not read further
+	Err error
+}
+
+// Pipeline splits the inputs into pages on the line that starts a new sheet and parses
+// the pages on a pool of workers. the inputs are split one after another, the parsing
+// of their pages is spread over all the workers. compressed and bundled inputs are
+// unpacked as they are split, see Unpack
+type Pipeline struct {
+	// number of pages parsed at once, runtime.NumCPU() when 0
+	Workers int
+
+	// when true the results come out in the order of the inputs and of the sheets in them,
+	// otherwise in the order they are done
+	Ordered bool
+
+	// most pages split off but not yet taken from the results, bounds the memory used.
+	// 4 * Workers when 0
+	InFlight int
+
+	// gives every sheet its Provenance, see Page.RecordProvenance
+	RecordProvenance bool
+}
+
+// a page of one input waiting to be parsed
+type chunk struct {
+	seq int
+	file string
+	data []byte
+
+	// the file in a bundled input the page is from, empty for a plain one
+	member string
+
+	// of the last program line before the page
+	program string
+	programVersion string
+
+	// lines and bytes of the input before the page
+	line int
+	offset int64
+
+	err error
+}
+
+type sequenced struct {
+	seq int
+	result Result
+}
+
+// Run parses the inputs and sends the sheets on the returned channel, which is closed
+// when all are done or the context is canceled
+func (pipeline Pipeline) Run (ctx context.Context, inputs []Input) <-chan Result {
+	workers := pipeline.Workers
+
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	inFlight := pipeline.InFlight
+
+	if inFlight <= 0 {
+		inFlight = 4 * workers
+	}
+
+	// a token is taken for each page split off and given back when its result is sent
+	tokens := make(chan struct{}, inFlight)
+	chunks := make(chan chunk, workers)
+	results := make(chan sequenced, workers)
+	out := make(chan Result)
+
+	go func () {
+		defer close(chunks)
+		seq := 0
+
+		for _, input := range inputs {
+			if !split(ctx, input, &seq, tokens, chunks) {
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+
+	for i := 0; i < workers; i++ {
+		go func () {
+			defer wg.Done()
+
+			for c := range chunks {
+				select {
+				case results <- sequenced{c.seq, pipeline.parse(c)}:
+				case <-ctx.Done(): return
+				}
+			}
+		}()
+	}
+
+	go func () {
+		wg.Wait()
+		close(results)
+	}()
+
+	go func () {
+		defer close(out)
+
+		// results that are done before the ones ahead of them, when ordered
+		waiting := make(map[int]Result)
+		next := 0
+
+		send := func (result Result) bool {
+			select {
+			case out <- result:
+				<-tokens
+				return true
+			case <-ctx.Done(): return false
+			}
+		}
+
+		for r := range results {
+			if !pipeline.Ordered {
+				if !send(r.result) {
+					return
+				}
+
+				continue
+			}
+
+			waiting[r.seq] = r.result
+
+			for {
+				result, ok := waiting[next]
+
+				if !ok {
+					break
+				}
+
+				delete(waiting, next)
+				next++
+
+				if !send(result) {
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+// parses the page with a Reader of its own, so the sheet is the same as reading the input in one go
+func (pipeline Pipeline) parse (c chunk) Result {
+	if c.err != nil {
+		return Result{File: c.file, Err: c.err}
+	}
+
+	reader := NewReader(bytes.NewReader(c.data))
+	reader.File = memberPath(c.file, c.member)
+	reader.Member = c.member
+	reader.LineNum = c.line
+	reader.offsets.offset = c.offset
+	reader.program = c.program
+	reader.programVersion = c.programVersion
+	reader.Page.RecordProvenance = pipeline.RecordProvenance
+
+	sheet, err := reader.Read()
+
+	return Result{Sheet: sheet, File: c.file, Offset: c.offset, Length: int64(len(c.data)), Line: c.line, Err: err}
+}
+
+// splits the input into pages and sends them to be parsed, false when the context was canceled.
+// a compressed or bundled input is unpacked and each of its members split in turn
+func split (ctx context.Context, input Input, seq *int, tokens chan struct{}, chunks chan chunk) bool {
+	send := func (c chunk) bool {
+		c.seq = *seq
+		c.file = input.Name
+
+		select {
+		case tokens <- struct{}{}:
+		case <-ctx.Done(): return false
+		}
+
+		select {
+		case chunks <- c:
+			*seq++
+			return true
+		case <-ctx.Done(): return false
+		}
+	}
+
+	r, err := input.Open()
+
+	if err != nil {
+		return send(chunk{err: err})
+	}
+
+	defer r.Close()
+
+	err = Unpack(r, func (member string, r io.Reader) error {
+		return splitPages(ctx, r, member, send)
+	})
+
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		return send(chunk{err: err})
+	}
+
+	return true
+}
+
+// sends every page of one file, split where the reader would start a sheet and with the lines
+// before the first page left out like it does. the context error when it was canceled
+func splitPages (ctx context.Context, r io.Reader, member string, send func (c chunk) bool) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Split(scanRawLines)
+
+	var current *chunk
+	var splitter sheetSplitter
+	var classifier lineClassifier
+	program, programVersion := "", ""
+	line := 0
+	offset := int64(0)
+
+	for scanner.Scan() {
+		raw := scanner.Bytes()
+		classified := classifier.classify(strings.TrimRight(string(raw), "\r\n"))
+
+		if start, _ := splitter.next(classified); start {
+			if current != nil && !send(*current) {
+				return ctx.Err()
+			}
+
+			current = &chunk{member: member, program: program, programVersion: programVersion, line: line, offset: offset}
+		}
+
+		if classified.kind == programLine {
+			program, programVersion = parseProgram(classified.text)
+		}
+
+		if current != nil {
+			current.data = append(current.data, raw...)
+		}
+
+		line++
+		offset += int64(len(raw))
+	}
+
+	if current != nil && !send(*current) {
+		return ctx.Err()
+	}
+
+	return scanner.Err()
+}
+
+// bufio.ScanLines, keeping the line ending so the page has the bytes of the input
+func scanRawLines (data []byte, atEOF bool) (int, []byte, error) {
+	advance, _, err := bufio.ScanLines(data, atEOF)
+
+	if advance > 0 {
+		return advance, data[:advance], err
+	}
+
+	return advance, nil, err
+}
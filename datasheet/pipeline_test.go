@@ -0,0 +1,169 @@
+package datasheet
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// every sheet of the corpus read one after another with a Reader, with provenance
+func readCorpus (t *testing.T, names []string) []DataSheet {
+	t.Helper()
+	sheets := make([]DataSheet, 0)
+
+	for _, name := range names {
+		file, err := os.Open(name)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r := NewReader(file)
+		r.Page.RecordProvenance = true
+
+		for {
+			sheet, err := r.Read()
+
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sheets = append(sheets, sheet)
+		}
+
+		file.Close()
+	}
+
+	return sheets
+}
+
+func corpusInputs () ([]string, []Input) {
+	names := make([]string, 0, len(corpus))
+
+	for name := range corpus {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	inputs := make([]Input, len(names))
+
+	for i, name := range names {
+		inputs[i] = FileInput(name)
+	}
+
+	return names, inputs
+}
+
+func TestPipelineOrdered (t *testing.T) {
+	names, inputs := corpusInputs()
+	want := readCorpus(t, names)
+
+	for _, workers := range []int{1, 2, 8} {
+		pipeline := Pipeline{Workers: workers, Ordered: true, InFlight: 2, RecordProvenance: true}
+		got := make([]DataSheet, 0)
+
+		for result := range pipeline.Run(context.Background(), inputs) {
+			if result.Err != nil {
+				t.Fatal(result.Err)
+			}
+
+			got = append(got, result.Sheet)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%d workers: the sheets are not the same as reading the files in order", workers)
+		}
+	}
+}
+
+func TestPipelineUnordered (t *testing.T) {
+	names, inputs := corpusInputs()
+	want := make(map[string]DataSheet)
+
+	for _, sheet := range readCorpus(t, names) {
+		want[sheet.Id] = sheet
+	}
+
+	got := make(map[string]DataSheet)
+
+	for result := range (Pipeline{Workers: 4}).Run(context.Background(), inputs) {
+		if result.Err != nil {
+			t.Fatal(result.Err)
+		}
+
+		if result.Sheet.Provenance != nil {
+			t.Errorf("%s: got provenance, want none", result.Sheet.Id)
+		}
+
+		sheet := want[result.Sheet.Id]
+		sheet.Provenance = nil
+		want[result.Sheet.Id] = sheet
+		got[result.Sheet.Id] = result.Sheet
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %d sheets, want the %d of the corpus", len(got), len(want))
+	}
+}
+
+func TestPipelineOpenError (t *testing.T) {
+	inputs := []Input{FileInput("testdata/missing.txt"), FileInput("testdata/cors.txt")}
+	results := make([]Result, 0)
+
+	for result := range (Pipeline{Ordered: true}).Run(context.Background(), inputs) {
+		results = append(results, result)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+
+	if !errors.Is(results[0].Err, os.ErrNotExist) || results[0].File != "testdata/missing.txt" {
+		t.Errorf("got %+v, want the missing file", results[0])
+	}
+
+	if results[1].Err != nil || results[1].Sheet.Id != "DF4370" || results[1].File != "testdata/cors.txt" {
+		t.Errorf("got %s %v, want DF4370 from the next file", results[1].Sheet.Id, results[1].Err)
+	}
+}
+
+func TestPipelineCancel (t *testing.T) {
+	content, err := os.ReadFile("testdata/first_order.txt")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a large input that would block without a reader of the results
+	input := Input{
+		Name: "many",
+		Open: func () (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(strings.Repeat(string(content), 1000))), nil
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results := (Pipeline{Workers: 2, Ordered: true}).Run(ctx, []Input{input})
+
+	<-results
+	cancel()
+
+	count := 0
+
+	for range results {
+		count++
+	}
+
+	if count >= 999 {
+		t.Errorf("got %d more results after canceling", count)
+	}
+}
@@ -0,0 +1,60 @@
+package datasheet
+
+import (
+	"fmt"
+)
+
+// Provenance is where a record of a sheet came from in the input
+type Provenance struct {
+	// name of the input, empty if the reader was not given one
+	File string `json:"file"`
+
+	// byte offset of the first line of the record
+	Offset int64 `json:"offset"`
+
+	// bytes from the start of the first line to the end of the last line
+	Length int64 `json:"length"`
+
+	// line numbers in the input, starting at 1, inclusive
+	StartLine int `json:"startLine"`
+	EndLine int `json:"endLine"`
+}
+
+// records the current line as the source of the record at the json path, ex: history[2].
+// if the record already has a source, the range is grown to take in the current line
+func (page *Page) mark (path string) {
+	if !page.RecordProvenance {
+		return
+	}
+
+	if page.CurrentSheet.Provenance == nil {
+		page.CurrentSheet.Provenance = make(map[string]Provenance)
+	}
+
+	prov, ok := page.CurrentSheet.Provenance[path]
+
+	if !ok {
+		page.CurrentSheet.Provenance[path] = Provenance{
+			File: page.SourceFile,
+			Offset: page.SourceOffset,
+			Length: page.SourceLength,
+			StartLine: page.SourceLine,
+			EndLine: page.SourceLine,
+		}
+
+		return
+	}
+
+	prov.EndLine = page.SourceLine
+	prov.Length = page.SourceOffset + page.SourceLength - prov.Offset
+	page.CurrentSheet.Provenance[path] = prov
+}
+
+// marks the count'th item of the array at the json path
+func (page *Page) markItem (path string, count int) {
+	if !page.RecordProvenance {
+		return
+	}
+
+	page.mark(fmt.Sprintf("%s[%d]", path, count - 1))
+}
@@ -0,0 +1,115 @@
+package datasheet
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestMark (t *testing.T) {
+	page := NewPage()
+	page.RecordProvenance = true
+	page.SourceFile = "CA.txt"
+
+	// two lines of one record, then the first item of an array
+	page.SourceOffset, page.SourceLength, page.SourceLine = 100, 40, 7
+	page.mark("stationDescription[0]")
+	page.SourceOffset, page.SourceLength, page.SourceLine = 140, 25, 8
+	page.mark("stationDescription[0]")
+	page.markItem("history", 1)
+
+	deepEqual(t, page.CurrentSheet.Provenance, map[string]Provenance{
+		"stationDescription[0]": {File: "CA.txt", Offset: 100, Length: 65, StartLine: 7, EndLine: 8},
+		"history[0]": {File: "CA.txt", Offset: 140, Length: 25, StartLine: 8, EndLine: 8},
+	})
+
+	page = NewPage()
+	page.mark("id")
+	page.markItem("history", 1)
+
+	if page.CurrentSheet.Provenance != nil {
+		t.Errorf("got %v without RecordProvenance", page.CurrentSheet.Provenance)
+	}
+}
+
+// name[3] of a json path
+var indexPattern = regexp.MustCompile(`^(.+)\[(\d+)\]$`)
+
+// every key of the provenance is a path to a value in the json of the sheet
+func TestProvenancePaths (t *testing.T) {
+	for name := range corpus {
+		file, err := os.Open(name)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r := NewReader(file)
+		r.Page.RecordProvenance = true
+
+		for {
+			sheet, err := r.Read()
+
+			if err != nil {
+				break
+			}
+
+			data, _ := json.Marshal(sheet)
+			var doc map[string]interface{}
+			json.Unmarshal(data, &doc)
+
+			if len(sheet.Provenance) == 0 {
+				t.Errorf("%s has no provenance", sheet.Id)
+			}
+
+			for path := range sheet.Provenance {
+				if !hasPath(doc, path) {
+					t.Errorf("%s: %s is not in the json of the sheet", sheet.Id, path)
+				}
+			}
+		}
+
+		file.Close()
+	}
+}
+
+// whether the dotted path, with [n] for array items, leads to a value
+func hasPath (doc map[string]interface{}, path string) bool {
+	var value interface{} = doc
+
+	for _, part := range strings.Split(path, ".") {
+		index := -1
+
+		if match := indexPattern.FindStringSubmatch(part); match != nil {
+			part = match[1]
+			index, _ = strconv.Atoi(match[2])
+		}
+
+		object, ok := value.(map[string]interface{})
+
+		if !ok {
+			return false
+		}
+
+		if value, ok = object[part]; !ok {
+			return false
+		}
+
+		if index < 0 {
+			continue
+		}
+
+		array, ok := value.([]interface{})
+
+		if !ok || index >= len(array) {
+			return false
+		}
+
+		value = array[index]
+	}
+
+	return value != nil
+}
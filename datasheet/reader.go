@@ -0,0 +1,248 @@
+package datasheet
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+)
+
+type Reader struct {
+	Scanner *bufio.Scanner
+
+	// the line that starts the next sheet, read by HasNext or Next and not yet given to it
+	BottomHeader string
+
+	Page Page
+
+	// number of lines read from the input so far
+	LineNum int
+
+	// problems found in the last sheet returned by Next
+	Errors ParseErrors
+
+	// name of the input used for provenance, set from the file when reading an *os.File
+	File string
+
+	// given to every sheet as its Member, for a reader over a member of a bundle, see Unpack
+	Member string
+
+	// where Seek finds the sheets
+	Index *OffsetIndex
+
+	input io.Reader
+	offsets *lineOffsets
+	splitter sheetSplitter
+	classifier lineClassifier
+
+	// from the last program line, given to every sheet after it
+	program string
+	programVersion string
+}
+
+// keeps track of the byte offset of each line the scanner hands out
+type lineOffsets struct {
+	offset int64
+	lineOffset int64
+	lineLength int64
+}
+
+func NewReader (r io.Reader) Reader {
+	offsets := &lineOffsets{}
+	file := ""
+
+	if named, ok := r.(interface{ Name() string }); ok {
+		file = named.Name()
+	}
+
+	return Reader{
+		Scanner: offsets.scanner(r),
+		BottomHeader: "",
+		Page: NewPage(),
+		File: file,
+		input: r,
+		offsets: offsets,
+	}
+}
+
+// HasNext reads up to the line that starts the next sheet and keeps it in BottomHeader,
+// false at the end of the input
+func (reader *Reader) HasNext () bool {
+	if len(reader.BottomHeader) > 0 {
+		return true
+	}
+
+	for reader.scan() {
+		line := reader.Scanner.Text()
+
+		if start, _ := reader.splitter.next(reader.classifier.classify(line)); start {
+			reader.BottomHeader = line
+			return true
+		}
+	}
+
+	return false
+}
+
+// Next reads the sheet that starts at BottomHeader, up to the line that starts the one after
+func (reader *Reader) Next () DataSheet {
+	if len(reader.BottomHeader) > 0 {
+		// a line that starts a sheet is data when it is not a header, ex: the first line of a file without one
+		classified := reader.classifier.classify(reader.BottomHeader)
+		reader.take(classified, classified.kind == dataLine)
+		reader.BottomHeader = ""
+	}
+
+	for reader.scan() {
+		line := reader.Scanner.Text()
+		classified := reader.classifier.classify(line)
+		start, data := reader.splitter.next(classified)
+
+		if start {
+			reader.BottomHeader = line
+			return reader.make()
+		}
+
+		reader.take(classified, data)
+	}
+
+	return reader.make()
+}
+
+// Read returns the next sheet, like encoding/csv. At the end of the input it
+// returns io.EOF. If the sheet had problems it is still returned along with a
+// ParseErrors so it can be logged or set aside, and the next call keeps going.
+// Any other error is from the underlying reader and stops the reading.
+func (reader *Reader) Read () (DataSheet, error) {
+	if !reader.HasNext() {
+		if err := reader.Err(); err != nil {
+			return DataSheet{}, err
+		}
+
+		return DataSheet{}, io.EOF
+	}
+
+	sheet := reader.Next()
+
+	if err := reader.Err(); err != nil {
+		return sheet, err
+	}
+
+	if len(reader.Errors) > 0 {
+		return sheet, reader.Errors
+	}
+
+	return sheet, nil
+}
+
+// Seek moves the reader to the sheet with the pid so the next Read returns it, the reads
+// after go on from there. the sheet is found in the Index and the input has to be an
+// io.Seeker, ex: an *os.File, with the sheet right in it and not in a bundle
+func (reader *Reader) Seek (pid PID) error {
+	if reader.Index == nil {
+		return ErrNotIndexed
+	}
+
+	location, ok := reader.Index.Lookup(pid)
+
+	if !ok {
+		return ErrNotIndexed
+	}
+
+	seeker, ok := reader.input.(io.Seeker)
+
+	if !ok || location.Member != "" {
+		return ErrNotSeekable
+	}
+
+	if reader.File != "" && location.File != "" && filepath.Clean(reader.File) != filepath.Clean(location.File) {
+		return fmt.Errorf("%s is in %s, not %s: %w", pid, location.File, reader.File, ErrNotIndexed)
+	}
+
+	if _, err := seeker.Seek(location.Offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	reader.offsets = &lineOffsets{offset: location.Offset}
+	reader.Scanner = reader.offsets.scanner(reader.input)
+	reader.LineNum = location.Line
+	reader.BottomHeader = ""
+	reader.splitter = sheetSplitter{}
+	reader.Page.Reset()
+
+	return nil
+}
+
+// Err is the first error from the underlying reader, ex: bufio.ErrTooLong
+func (reader *Reader) Err () error {
+	return reader.Scanner.Err()
+}
+
+// scans the next line and keeps count of where we are
+func (reader *Reader) scan () bool {
+	if !reader.Scanner.Scan() {
+		return false
+	}
+
+	reader.LineNum++
+	return true
+}
+
+// tells the page where the current line is in the input
+func (reader *Reader) setSource () {
+	reader.Page.SourceLine = reader.LineNum
+	reader.Page.SourceFile = reader.File
+
+	if reader.offsets != nil {
+		reader.Page.SourceOffset = reader.offsets.lineOffset
+		reader.Page.SourceLength = reader.offsets.lineLength
+	}
+}
+
+// gives the data lines to the page, the program of a program line is kept for the sheet
+// and the ones after it
+func (reader *Reader) take (line sheetLine, data bool) {
+	if line.kind == programLine {
+		reader.program, reader.programVersion = parseProgram(line.text)
+	}
+
+	if data {
+		reader.setSource()
+		reader.Page.AddLine(line.text)
+	}
+}
+
+// makes the sheet and holds on to its errors, the ones found when it is finished too
+func (reader *Reader) make () DataSheet {
+	reader.Page.finish()
+	reader.Errors = reader.Page.Errors
+	sheet := reader.Page.CurrentSheet
+	reader.Page.Reset()
+
+	sheet.Member = reader.Member
+	sheet.Program = reader.program
+	sheet.ProgramVersion = reader.programVersion
+
+	return sheet
+}
+
+// a scanner over r that counts the bytes of the lines it hands out
+func (offsets *lineOffsets) scanner (r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Split(offsets.scanLines)
+
+	return scanner
+}
+
+// bufio.ScanLines, counting the bytes of each line
+func (offsets *lineOffsets) scanLines (data []byte, atEOF bool) (int, []byte, error) {
+	advance, token, err := bufio.ScanLines(data, atEOF)
+
+	if advance > 0 {
+		offsets.lineOffset = offsets.offset
+		offsets.lineLength = int64(advance)
+		offsets.offset += int64(advance)
+	}
+
+	return advance, token, err
+}
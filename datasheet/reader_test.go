@@ -0,0 +1,467 @@
+package datasheet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// the sheets we expect in each file of the corpus, in order. the files are written by hand to
+// the columns of dsdata.pdf, they are not retrievals
+var corpus = map[string][]string{
+	// first order horizontal station with spc, azimuth marks, reference objects and superseded data
+	"testdata/first_order.txt": {"HV4612"},
+
+	// gps cors tied mark with network accuracy, utm only and no superseded data
+	"testdata/cors.txt": {"DF4370"},
+
+	// a scaled bench mark with superseded heights and a destroyed mark without spc
+	"testdata/bench_marks.txt": {"KV0001", "KV0002"},
+}
+
+func readAll (t *testing.T, name string) []DataSheet {
+	t.Helper()
+
+	file, err := os.Open(name)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	r := NewReader(file)
+	sheets := make([]DataSheet, 0)
+
+	for {
+		sheet, err := r.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		sheets = append(sheets, sheet)
+	}
+
+	return sheets
+}
+
+func TestGolden (t *testing.T) {
+	for name, pids := range corpus {
+		sheets := readAll(t, name)
+
+		if len(sheets) != len(pids) {
+			t.Fatalf("%s: got %d sheets, want %d", name, len(sheets), len(pids))
+		}
+
+		for i, sheet := range sheets {
+			if sheet.Id != pids[i] {
+				t.Errorf("%s: sheet %d is %s, want %s", name, i, sheet.Id, pids[i])
+			}
+
+			checkGolden(t, sheet)
+		}
+	}
+}
+
+// compares the sheet to testdata/golden/<pid>.json, or rewrites it with -update
+func checkGolden (t *testing.T, sheet DataSheet) {
+	t.Helper()
+
+	got, err := json.MarshalIndent(sheet, "", "  ")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got = append(got, '\n')
+	path := filepath.Join("testdata", "golden", sheet.Id + ".json")
+
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		return
+	}
+
+	want, err := os.ReadFile(path)
+
+	if err != nil {
+		t.Fatalf("%v, run go test -update to make it", err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s does not match the parsed sheet, run go test -update and review the diff\n%s", path, got)
+	}
+}
+
+// values of HV4612 read by hand off the columns of testdata/first_order.txt, so a golden
+// rewritten with -update can not take a wrong value along with it
+func TestFirstOrderByHand (t *testing.T) {
+	sheet := readAll(t, "testdata/first_order.txt")[0]
+	dms := func (d float64, m float64, s float64) float64 { return d + m / 60 + s / 3600 }
+	near := func (a float64, b float64) bool { return math.Abs(a - b) < 1e-9 }
+
+	tests := []struct {
+		name string
+		got float64
+		want float64
+	}{
+		// 32 43 05.51283(N) 117 08 20.13749(W)
+		{"lat", sheet.Position.Lat, dms(32, 43, 5.51283)},
+		{"lon", sheet.Position.Lon, -dms(117, 8, 20.13749)},
+		{"epoch", sheet.Position.Epoch, 2010},
+		{"ellipsoid height", sheet.EllipsoidHeight.Height, 97.632},
+		{"orthometric height", sheet.OrthometricHeight.Height, 131.8},
+		{"orthometric feet", sheet.OrthometricHeight.Feet, 432},
+		{"geoid height", sheet.GeoidHeight.Height, -34.37},
+		{"x", sheet.XYZ.X, -2460219.420},
+		{"z", sheet.XYZ.Z, 3432997.151},
+		{"network horiz", sheet.Accuracy.Network[0].Horiz, 1.41},
+		{"network corrNE", sheet.Accuracy.Network[0].CorrNE, -0.01987546},
+
+		// SPC CA 6 - 1,840,946.512 1,931,213.880 MT 0.99994478 -0 09 47.9
+		{"spc north", sheet.StatePlaneCoordinates[0].North, 1840946.512},
+		{"spc east", sheet.StatePlaneCoordinates[0].East, 1931213.880},
+		{"spc scale", sheet.StatePlaneCoordinates[0].Scale, 0.99994478},
+		{"spc convergence", sheet.StatePlaneCoordinates[0].Convergence, -dms(0, 9, 47.9)},
+
+		// the same in us survey feet, 1200/3937 of a meter
+		{"spc feet north", sheet.StatePlaneCoordinates[1].NorthMeters, 6039844.31 * 1200 / 3937},
+		{"utm east", sheet.StatePlaneCoordinates[2].East, 487030.542},
+
+		// MOUNT HOPE AZ MK 178 31 17.6, the reference object at 1782129.6
+		{"azimuth mark", sheet.PrimaryAzimuthMarks[0].Azimuth, dms(178, 31, 17.6)},
+		{"reference azimuth", *sheet.ReferenceObjects[0].Azimuth, dms(178, 21, 29.6)},
+		{"reference distance", sheet.ReferenceObjects[1].Length.Meters, 12.395},
+		{"superseded ellipsoid height", sheet.SurveyEllipsoidHeights[0].Height, 97.650},
+	}
+
+	for _, tt := range tests {
+		if !near(tt.got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	strs := []struct {
+		name string
+		got string
+		want string
+	}{
+		{"designation", sheet.BasicMetadata["DESIGNATION"], "MOUNT HOPE"},
+		{"county", sheet.BasicMetadata["STATE/COUNTY"], "CA/SAN DIEGO"},
+		{"datum", sheet.Position.Datum + "(" + sheet.Position.Realization + ")", "NAD 83(2011)"},
+		{"geoid model", sheet.GeoidHeight.Model, "GEOID18"},
+		{"spc zone", sheet.StatePlaneCoordinates[0].Zone, "CA 6"},
+		{"spatial address", sheet.SpatialAddress, "11SMS8703025992(NAD 83)"},
+		{"reference pid", sheet.ReferenceObjects[3].Pid, "HV4599"},
+		{"marker", sheet.Monument.MarkerCode, "DD"},
+		{"setting", sheet.Monument.SettingCode, "7"},
+		{"stability", sheet.Monument.Stability, "MAY HOLD, BUT OF TYPE COMMONLY SUBJECT TO SURFACE MOTION"},
+		{"latest history", sheet.History[len(sheet.History) - 1].Date + " " + sheet.History[len(sheet.History) - 1].Condition, "20080312 GOOD"},
+	}
+
+	for _, tt := range strs {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+
+	if len(sheet.History) != 3 || len(sheet.StationRecoveries) != 2 || len(sheet.SurveyLatitudeLongitudes) != 3 {
+		t.Errorf("got %d history rows, %d recoveries and %d superseded positions, want 3, 2 and 3", len(sheet.History), len(sheet.StationRecoveries), len(sheet.SurveyLatitudeLongitudes))
+	}
+}
+
+func TestReadParseErrors (t *testing.T) {
+	input := strings.Join([]string{
+		"1        National Geodetic Survey,   Retrieval Date = JANUARY 3, 2020",
+		" AB1234 ***********************************************************************",
+		" AB1234  DESIGNATION -  BAD NETWORK",
+		" AB1234",
+		" AB1234",
+		" AB1234  NETWORK   1.41   2.67   0.61",
+		"1        National Geodetic Survey,   Retrieval Date = JANUARY 3, 2020",
+		" AB1235 ***********************************************************************",
+		" AB1235  DESIGNATION -  GOOD",
+	}, "\n")
+
+	r := NewReader(strings.NewReader(input))
+
+	sheet, err := r.Read()
+	errs, ok := err.(ParseErrors)
+
+	if !ok || len(errs) != 1 {
+		t.Fatalf("got %v, want one parse error", err)
+	}
+
+	want := &ParseError{Pid: "AB1234", Line: 6, Section: "accuracySection", Err: ErrNetworkAccuracy}
+
+	if !reflect.DeepEqual(errs[0], want) {
+		t.Errorf("got %+v, want %+v", errs[0], want)
+	}
+
+	if !errors.Is(errs[0], ErrNetworkAccuracy) {
+		t.Error("parse error does not unwrap to ErrNetworkAccuracy")
+	}
+
+	if sheet.Id != "AB1234" {
+		t.Errorf("bad sheet was not returned, got %q", sheet.Id)
+	}
+
+	sheet, err = r.Read()
+
+	if err != nil || sheet.BasicMetadata["DESIGNATION"] != "GOOD" {
+		t.Errorf("got %q %v, want the next sheet", sheet.BasicMetadata["DESIGNATION"], err)
+	}
+
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("got %v, want io.EOF", err)
+	}
+}
+
+func TestReadMissingId (t *testing.T) {
+	input := strings.Join([]string{
+		"1        National Geodetic Survey,   Retrieval Date = JANUARY 3, 2020",
+		"         DESIGNATION -  NO PID",
+		"         MARKER: DD = SURVEY DISK",
+		"1        National Geodetic Survey,   Retrieval Date = JANUARY 3, 2020",
+		" AB1235 ***********************************************************************",
+		" AB1235  DESIGNATION -  GOOD",
+	}, "\n")
+
+	r := NewReader(strings.NewReader(input))
+
+	sheet, err := r.Read()
+	errs, ok := err.(ParseErrors)
+
+	if !ok || len(errs) != 1 || !errors.Is(errs[0], ErrMissingId) {
+		t.Fatalf("got %v, want ErrMissingId", err)
+	}
+
+	if sheet.Id != "" {
+		t.Errorf("got pid %q from a blank pid column", sheet.Id)
+	}
+
+	sheet, err = r.Read()
+
+	if err != nil || sheet.Id != "AB1235" {
+		t.Errorf("got %q %v, want the next sheet", sheet.Id, err)
+	}
+}
+
+func TestBoundaries (t *testing.T) {
+	title := "1        National Geodetic Survey,   Retrieval Date = JANUARY 3, 2020"
+
+	tests := []struct {
+		name string
+		lines []string
+
+		// pid, designation, program and version of each sheet
+		want []string
+	}{
+		{"no stars", []string{
+			title,
+			" AB1234  DESIGNATION -  FIRST",
+			title,
+			" AB1235  DESIGNATION -  SECOND",
+		}, []string{"AB1234 FIRST", "AB1235 SECOND"}},
+		{"form feeds", []string{
+			"\f",
+			" AB1234 ***********************",
+			" AB1234  DESIGNATION -  FIRST",
+			"\f AB1235 ***********************",
+			" AB1235  DESIGNATION -  SECOND",
+			"\f AB1236  DESIGNATION -  THIRD",
+		}, []string{"AB1234 FIRST", "AB1235 SECOND", "AB1236 THIRD"}},
+		{"no carriage control", []string{
+			"         National Geodetic Survey,   Retrieval Date = JANUARY 3, 2020",
+			"AB1234 ***********************",
+			"AB1234  DESIGNATION -  FIRST",
+			"AB1234",
+			"AB1235 ***********************",
+			"AB1235  DESIGNATION -  SECOND",
+		}, []string{"AB1234 FIRST", "AB1235 SECOND"}},
+		{"single sheet", []string{
+			" AB1234  DESIGNATION -  ONLY",
+			" AB1234  PID         -  AB1234",
+		}, []string{"AB1234 ONLY"}},
+		{"program", []string{
+			" The NGS Data Sheet",
+			"",
+			" See file dsdata.pdf for more information about the datasheet.",
+			"",
+			" DATASHEETS - PROGRAM = datasheet95, VERSION = 8.12.5.3",
+			title,
+			"",
+			" AB1234 ***********************",
+			" AB1234  DESIGNATION -  FIRST",
+			title,
+			" PROGRAM = datasheet96",
+			" AB1235 ***********************",
+			" AB1235  DESIGNATION -  SECOND",
+			" PROGRAM = datasheet97, VERSION = 9",
+			" AB1236  DESIGNATION -  THIRD",
+		}, []string{"AB1234 FIRST datasheet95 8.12.5.3", "AB1235 SECOND datasheet96", "AB1236 THIRD datasheet97 9"}},
+		{"crlf", []string{
+			title + "\r",
+			" AB1234 ***********************\r",
+			" AB1234  DESIGNATION -  FIRST\r",
+		}, []string{"AB1234 FIRST"}},
+	}
+
+	describe := func (sheet DataSheet) string {
+		return strings.TrimSpace(strings.Join([]string{sheet.Id, sheet.BasicMetadata["DESIGNATION"], sheet.Program, sheet.ProgramVersion}, " "))
+	}
+
+	for _, tt := range tests {
+		input := strings.Join(tt.lines, "\n") + "\n"
+		r := NewReader(strings.NewReader(input))
+		got := make([]string, 0)
+
+		for {
+			sheet, err := r.Read()
+
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+
+			got = append(got, describe(sheet))
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: the reader got %q, want %q", tt.name, got, tt.want)
+		}
+
+		// the pipeline splits the same way
+		got = got[:0]
+
+		for _, result := range runInput(t, bytesInput(tt.name, []byte(input))) {
+			got = append(got, describe(result.Sheet))
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: the pipeline got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// a file saved without the carriage control column reads like the one with it, every
+// section of the sheets and not only the lines with a space after the pid
+func TestNoCarriageControl (t *testing.T) {
+	for name, pids := range corpus {
+		data, err := os.ReadFile(name)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		lines := strings.Split(string(data), "\n")
+
+		for i, line := range lines {
+			if line != "" {
+				lines[i] = line[1:]
+			}
+		}
+
+		input := []byte(strings.Join(lines, "\n"))
+		sheets := readSheets(t, bytes.NewReader(input))
+
+		for _, result := range runInput(t, bytesInput(name, input)) {
+			result.Sheet.Provenance = nil
+			sheets = append(sheets, result.Sheet)
+		}
+
+		if len(sheets) != 2 * len(pids) {
+			t.Fatalf("%s: got %d sheets from the reader and the pipeline, want %d each", name, len(sheets), len(pids))
+		}
+
+		for _, sheet := range sheets {
+			checkGolden(t, sheet)
+		}
+	}
+}
+
+func TestReadTooLong (t *testing.T) {
+	input := "1\n AB1234 ***\n AB1234  DESIGNATION -  " + strings.Repeat("X", bufio.MaxScanTokenSize) + "\n"
+	r := NewReader(strings.NewReader(input))
+
+	if _, err := r.Read(); err != bufio.ErrTooLong {
+		t.Errorf("got %v, want bufio.ErrTooLong", err)
+	}
+}
+
+func TestProvenance (t *testing.T) {
+	file, err := os.Open("testdata/first_order.txt")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	r := NewReader(file)
+	r.Page.RecordProvenance = true
+
+	sheet, err := r.Read()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile("testdata/first_order.txt")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		startLine int
+		endLine int
+		text string
+	}{
+		{"metadata.DESIGNATION", 3, 3, "DESIGNATION -  MOUNT HOPE"},
+		{"position", 11, 13, "NAD 83(2011) POSITION"},
+		{"history[1]", 90, 90, "1972     GOOD"},
+		{"stationDescription[0]", 93, 97, "MOUNT HOPE CEMETERY"},
+	}
+
+	for _, tt := range tests {
+		prov, ok := sheet.Provenance[tt.path]
+
+		if !ok {
+			t.Errorf("%s has no provenance", tt.path)
+			continue
+		}
+
+		if prov.File != "testdata/first_order.txt" || prov.StartLine != tt.startLine || prov.EndLine != tt.endLine {
+			t.Errorf("%s: got %+v, want lines %d-%d", tt.path, prov, tt.startLine, tt.endLine)
+		}
+
+		text := string(content[prov.Offset:prov.Offset + prov.Length])
+
+		if !strings.Contains(text, tt.text) {
+			t.Errorf("%s: bytes %d+%d are %q, want them to have %q", tt.path, prov.Offset, prov.Length, text, tt.text)
+		}
+	}
+}
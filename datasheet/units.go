@@ -0,0 +1,34 @@
+package datasheet
+
+import (
+	"strings"
+)
+
+// meters in a foot, heights on the sheets are in u.s. survey feet
+var (
+	surveyFoot = 1200.0 / 3937.0
+	internationalFoot = 0.3048
+)
+
+// the value in meters, false when the unit is not a length we know, ex: m, meters, f, ft, feet
+func toMeters (value float64, unit string) (float64, bool) {
+	switch strings.ToLower(trimWhiteSpace(unit)) {
+	case "m", "mt", "meters": return value, true
+	case "f", "ft", "feet", "sft": return value * surveyFoot, true
+	case "ift": return value * internationalFoot, true
+	case "km": return value * 1000, true
+	}
+
+	return 0, false
+}
+
+// the value in u.s. survey feet, false when the unit is not a length we know
+func toFeet (value float64, unit string) (float64, bool) {
+	meters, ok := toMeters(value, unit)
+
+	if !ok {
+		return 0, false
+	}
+
+	return meters / surveyFoot, true
+}
@@ -0,0 +1,33 @@
+package datasheet
+
+import (
+	"math"
+	"testing"
+)
+
+func TestToMeters (t *testing.T) {
+	tests := []struct {
+		value float64
+		unit string
+		want float64
+		ok bool
+	}{
+		{266.87, "m", 266.87, true},
+		{3937, "f", 1200, true},
+		{3937, "sFT", 1200, true},
+		{10, "iFT", 3.048, true},
+		{1, "chains", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := toMeters(tt.value, tt.unit)
+
+		if ok != tt.ok || math.Abs(got - tt.want) > 1e-9 {
+			t.Errorf("toMeters(%v, %q) = %v %v, want %v %v", tt.value, tt.unit, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	if feet, _ := toFeet(1200, "m"); math.Abs(feet - 3937) > 1e-9 {
+		t.Errorf("toFeet(1200, m) = %v, want 3937", feet)
+	}
+}
@@ -0,0 +1,564 @@
+package datasheet
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// the widest a line of wrapped text gets after the pid
+var wrapWidth = 70
+
+// order of the monumentation keys on the sheet, keys not listed go after them sorted
+var monumentationOrder = []string{
+	"_MARKER",
+	"_SETTING",
+	"_SP_SET",
+	"_STAMPING",
+	"_MARK LOGO",
+	"_PROJECTION",
+	"_MAGNETIC",
+	"_STABILITY",
+	"_SATELLITE",
+	"_ROD/PIPE-DEPTH",
+	"_SLEEVE-DEPTH",
+}
+
+// Writer writes sheets in the fixed width dsdata layout that Reader reads,
+// so a sheet that is read, written and read again comes back the same
+type Writer struct {
+	buf *bufio.Writer
+	pid string
+	err error
+}
+
+// text put at a column of a line
+type field struct {
+	col int
+	text string
+}
+
+func NewWriter (w io.Writer) *Writer {
+	return &Writer{
+		buf: bufio.NewWriter(w),
+	}
+}
+
+// Write writes the sheet as one page, the error is from the underlying writer
+func (writer *Writer) Write (sheet DataSheet) error {
+	writer.pid = sheet.Id
+
+	if len(writer.pid) != 6 {
+		writer.pid = (writer.pid + "      ")[:6]
+	}
+
+	writer.raw("1        National Geodetic Survey")
+
+	if sheet.Program != "" {
+		program := " DATASHEETS - PROGRAM = " + sheet.Program
+
+		if sheet.ProgramVersion != "" {
+			program += ", VERSION = " + sheet.ProgramVersion
+		}
+
+		writer.raw(program)
+	}
+
+	writer.raw(" " + writer.pid + " " + strings.Repeat("*", 71))
+
+	writer.basicMetadata(sheet)
+	writer.surveyControl(sheet)
+	writer.accuracy(sheet)
+	writer.methodology(sheet)
+	writer.projections(sheet)
+	writer.azimuthMarks(sheet)
+	writer.superseded(sheet)
+	writer.monumentation(sheet)
+	writer.history(sheet)
+	writer.descriptionAndRecovery(sheet)
+
+	return writer.err
+}
+
+// Flush writes any buffered data to the underlying writer
+func (writer *Writer) Flush () error {
+	if writer.err != nil {
+		return writer.err
+	}
+
+	return writer.buf.Flush()
+}
+
+func (writer *Writer) raw (line string) {
+	if writer.err != nil {
+		return
+	}
+
+	_, writer.err = writer.buf.WriteString(line + "\n")
+}
+
+// writes " PID" and the mark, then each field at its column. the line is padded to
+// the column of the last field, so an empty field keeps the line long enough to be read
+func (writer *Writer) line (mark string, fields ...field) {
+	line := []byte(" " + writer.pid + mark)
+
+	for _, f := range fields {
+		for len(line) < f.col {
+			line = append(line, ' ')
+		}
+
+		line = append(line[:f.col], []byte(f.text)...)
+	}
+
+	writer.raw(string(line))
+}
+
+func (writer *Writer) blank () {
+	writer.line("")
+}
+
+func (writer *Writer) basicMetadata (sheet DataSheet) {
+	keys := make([]string, 0, len(sheet.BasicMetadata))
+
+	for key := range sheet.BasicMetadata {
+		keys = append(keys, key)
+	}
+
+	// these are at the top of every sheet, the rest go after
+	first := map[string]int{"DESIGNATION": 1, "PID": 2, "STATE/COUNTY": 3, "COUNTRY": 4, "USGS QUAD": 5}
+
+	sort.Slice(keys, func (i int, j int) bool {
+		a, b := first[keys[i]], first[keys[j]]
+
+		if a != b {
+			return a != 0 && (b == 0 || a < b)
+		}
+
+		return keys[i] < keys[j]
+	})
+
+	for _, key := range keys {
+		writer.line(" ", field{9, padRight(key, 12) + "-  " + sheet.BasicMetadata[key]})
+	}
+
+	writer.blank()
+}
+
+func (writer *Writer) surveyControl (sheet DataSheet) {
+	writer.line(" ", field{33, "*CURRENT SURVEY CONTROL"})
+	writer.line(" ", field{9, strings.Repeat("_", 70)})
+
+	for _, survey := range sheet.NewSurveyControl {
+		writer.line("*", field{9, padRight(survey.Item, 21) + "- " + survey.Value}, field{71, survey.By})
+	}
+
+	writer.line(" ", field{9, strings.Repeat("_", 70)})
+
+	for _, survey := range sheet.OldSurveyControl {
+		writer.line(" ", field{9, padRight(survey.Item, 16) + "- " + survey.Value}, field{71, survey.By})
+	}
+
+	writer.blank()
+}
+
+func (writer *Writer) accuracy (sheet DataSheet) {
+	orders := []struct {
+		key string
+		values []string
+	}{
+		{horzOrderKey, sheet.Accuracy.HorzOrder},
+		{vertOrderKey, sheet.Accuracy.VertOrder},
+		{ellpOrderKey, sheet.Accuracy.EllpOrder},
+	}
+
+	for _, order := range orders {
+		for _, value := range order.values {
+			writer.line(" ", field{9, padRight(order.key, 16) + "-  " + value})
+		}
+	}
+
+	if len(sheet.Accuracy.Network) > 0 {
+		writer.blank()
+		writer.line(" ", field{9, "FGDC (95% conf, cm)     Standard deviation (cm)     CorrNE"})
+		writer.line(" ", field{9, "         Horiz  Ellip   SD_N   SD_E   SD_h          (unitless)"})
+		writer.line(" ", field{9, strings.Repeat("-", 67)})
+
+		for _, ntw := range sheet.Accuracy.Network {
+			nums := []float64{ntw.Horiz, ntw.Ellip, ntw.SDN, ntw.SDE, ntw.SDH}
+			text := networkKey + " "
+
+			for _, n := range nums {
+				text = text + padLeft(formatNumber(n), 7)
+			}
+
+			writer.line(" ", field{9, text + "     " + formatNumber(ntw.CorrNE)})
+		}
+
+		writer.line(" ", field{9, strings.Repeat("-", 67)})
+	}
+
+	writer.blank()
+}
+
+func (writer *Writer) methodology (sheet DataSheet) {
+	for _, paragraph := range sheet.DeterminationMethodology {
+		for _, text := range wrapText(paragraph, wrapWidth) {
+			writer.line(".", field{8, text})
+		}
+
+		writer.blank()
+	}
+}
+
+func (writer *Writer) projections (sheet DataSheet) {
+	header := ""
+
+	for _, spc := range sheet.StatePlaneCoordinates {
+		estimated := spc.Estimated != ""
+
+		// the header tells the reader how to read the rows after it
+		if estimated && header != accuracyHeader {
+			if header != "" {
+				writer.blank()
+			}
+
+			header = accuracyHeader
+			writer.line(";", field{28, header})
+		} else if !estimated && header != statePlaneHeader {
+			if header != "" {
+				writer.blank()
+			}
+
+			header = statePlaneHeader
+			writer.line(";", field{28, header})
+		}
+
+		system := spc.System
+
+		if system == "" {
+			system = "SPC"
+		}
+
+		fields := []field{
+			{8, cut(trimWhiteSpace(system + " " + spc.Zone), 12)},
+			{21, "-"},
+			{22, padLeft(formatNumber(spc.North), 14)},
+			{36, padLeft(formatNumber(spc.East), 14)},
+			{52, padLeft(spc.Units, 3)},
+		}
+
+		if estimated {
+			fields = append(fields, field{57, spc.Estimated})
+		} else if spc.Scale != 0 || len(spc.Converg) > 0 {
+			fields = append(fields, field{57, flagged(formatNumber(spc.Scale), spc.ScaleFlagged)})
+
+			// the degrees are in Factor, a row without minutes has no convergence
+			if len(spc.Converg) > 0 {
+				converg := []string{formatNumber(spc.Factor)}
+
+				for _, n := range spc.Converg {
+					converg = append(converg, padLeft(formatNumber(n), 2))
+				}
+
+				fields = append(fields, field{69, flagged(strings.Join(converg, " "), spc.ConvergenceFlagged)})
+			}
+		}
+
+		writer.line(";", fields...)
+	}
+
+	if header != "" {
+		writer.blank()
+	}
+
+	if sheet.SpatialAddress != "" {
+		writer.line("_", field{8, spatialAddressKey + ": "}, field{44, sheet.SpatialAddress})
+		writer.blank()
+	}
+}
+
+func (writer *Writer) azimuthMarks (sheet DataSheet) {
+	if len(sheet.PrimaryAzimuthMarks) > 0 {
+		writer.line(":", field{25, "Primary Azimuth Mark"}, field{67, "Grid Az"})
+
+		for _, mark := range sheet.PrimaryAzimuthMarks {
+			az := make([]string, len(mark.GridAz))
+
+			for i, n := range mark.GridAz {
+				az[i] = formatNumber(n)
+			}
+
+			system := mark.System
+
+			if system == "" {
+				system = "SPC"
+			}
+
+			label := cut(trimWhiteSpace(system + " " + mark.Zone), 12)
+			writer.line(":", field{8, label}, field{21, "-"}, field{25, mark.Mark}, field{66, strings.Join(az, " ")})
+		}
+
+		writer.blank()
+	}
+
+	if len(sheet.ReferenceObjects) > 0 {
+		border := []field{{8, strings.Repeat("-", 69)}, {77, "|"}}
+
+		writer.line("|", border...)
+		writer.line("|", field{9, "PID    Reference Object"}, field{53, "Distance"}, field{67, "Geod. Az"}, field{77, "|"})
+		writer.line("|", field{67, "dddmmss.s"}, field{77, "|"})
+
+		for _, ref := range sheet.ReferenceObjects {
+			writer.line("|",
+				field{9, padRight(ref.Pid, 6)},
+				field{16, cut(ref.Ref, 36)},
+				field{52, cut(ref.Distance, 15)},
+				field{67, cut(ref.GeodAz, 9)},
+				field{77, "|"},
+			)
+		}
+
+		writer.line("|", border...)
+		writer.blank()
+	}
+}
+
+func (writer *Writer) superseded (sheet DataSheet) {
+	writer.line(" ", field{33, surveyControlHeader})
+	writer.blank()
+
+	empty := len(sheet.SurveyLatitudeLongitudes) == 0 && len(sheet.SurveyEllipsoidHeights) == 0 && len(sheet.SurveyOrthometricHeights) == 0
+
+	if empty {
+		writer.line(surveyControlEndB)
+		writer.blank()
+		return
+	}
+
+	for _, latLng := range sheet.SurveyLatitudeLongitudes {
+		writer.line(" ",
+			field{9, padRight(latLng.Name, 12)},
+			field{21, "-"},
+			field{24, padRight(latLng.Pos, 17)},
+			field{45, padRight(latLng.Body, 30)},
+			field{76, padRight(latLng.Order, 1)},
+		)
+	}
+
+	for _, ellipH := range sheet.SurveyEllipsoidHeights {
+		order := padRight(ellipH.Order, 1)
+
+		// the class number follows the order
+		if ellipH.Accuracy != nil {
+			for i, class := range numberedClasses {
+				if class == ellipH.Accuracy.Class {
+					order = order + " " + strconv.Itoa(i)
+				}
+			}
+		}
+
+		writer.line(" ",
+			field{9, ellipHKey},
+			field{17, "(" + padRight(ellipH.Date, 8) + ")"},
+			field{30, padLeft(formatNumber(ellipH.Height), 6)},
+			field{37, "(" + ellipH.Unit + ")"},
+			field{64, padRight(ellipH.Method, 14)},
+			field{78, order},
+		)
+	}
+
+	for _, navdH := range sheet.SurveyOrthometricHeights {
+		orders := make([]string, len(navdH.Order))
+
+		for i, n := range navdH.Order {
+			orders[i] = formatNumber(n)
+		}
+
+		datum := navdH.Datum
+
+		if datum == "" {
+			datum = verticalDatums[0]
+		}
+
+		// the height in the other unit, or the model when there is no other unit
+		other := []field{{43, cut(navdH.Model, 20)}}
+
+		if navdH.Unit == "m" {
+			other = []field{{46, cut(formatNumber(navdH.Feet), 14)}, {60, "(f)"}}
+		} else if navdH.Unit == "f" {
+			other = []field{{46, cut(formatNumber(navdH.Meters), 14)}, {60, "(m)"}}
+		}
+
+		fields := []field{
+			{9, datum},
+			{17, "(" + padRight(navdH.Date, 8) + ")"},
+			{29, padLeft(formatNumber(navdH.Height), 7)},
+			{37, "(" + navdH.Unit + ")"},
+		}
+
+		fields = append(fields, other...)
+		fields = append(fields, field{64, navdH.Method}, field{76, padRight(strings.Join(orders, " "), 3)})
+		writer.line(" ", fields...)
+	}
+
+	writer.blank()
+	writer.line(".", field{8, "Superseded values are not recommended for survey control."})
+	writer.blank()
+	writer.line(surveyControlEndA)
+	writer.blank()
+}
+
+func (writer *Writer) monumentation (sheet DataSheet) {
+	keys := make([]string, 0, len(sheet.Monumentation))
+
+	for key := range sheet.Monumentation {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func (i int, j int) bool {
+		return monumentationLess(keys[i], keys[j])
+	})
+
+	for _, key := range keys {
+		// a long value carries on to + lines
+		mark := key
+
+		for _, text := range wrapText(sheet.Monumentation[key], wrapWidth - len(key)) {
+			writer.line(mark + ": " + text)
+			mark = "+" + key[1:]
+		}
+
+		if sheet.Monumentation[key] == "" {
+			writer.line(key + ":")
+		}
+	}
+
+	writer.blank()
+}
+
+func (writer *Writer) history (sheet DataSheet) {
+	writer.line(" ", field{9, "HISTORY     - "}, field{23, historyHeader})
+
+	for _, history := range sheet.History {
+		// the by column is always there so the condition is not cut short
+		writer.line(" ",
+			field{9, "HISTORY     - "},
+			field{23, padRight(history.Date, 8)},
+			field{32, padRight(history.Condition, 17)},
+			field{49, history.By},
+		)
+	}
+
+	writer.blank()
+}
+
+func (writer *Writer) descriptionAndRecovery (sheet DataSheet) {
+	for _, desc := range sheet.StationDescription {
+		writer.line(" ", field{33, stationDescriptionHeader})
+		writer.blank()
+
+		for _, text := range wrapText(desc.Description, wrapWidth) {
+			writer.line("'", field{8, text})
+		}
+
+		writer.blank()
+	}
+
+	for _, rec := range sheet.StationRecoveries {
+		writer.line(" ", field{33, stationRevoveryHeader + " (" + rec.Date + ")"})
+		writer.blank()
+
+		for _, text := range wrapText(rec.Description, wrapWidth) {
+			writer.line("'", field{8, text})
+		}
+
+		writer.blank()
+	}
+}
+
+// sorts the monumentation keys like the sheet
+func monumentationLess (a string, b string) bool {
+	rankA, rankB := len(monumentationOrder), len(monumentationOrder)
+
+	for i, key := range monumentationOrder {
+		if key == a {
+			rankA = i
+		}
+
+		if key == b {
+			rankB = i
+		}
+	}
+
+	if rankA != rankB {
+		return rankA < rankB
+	}
+
+	return a < b
+}
+
+// splits the text on spaces into lines no wider than width, a long word gets a line of its own.
+// a line is never shorter than 2 characters, the reader skips those
+func wrapText (text string, width int) []string {
+	lines := make([]string, 0)
+	current := ""
+
+	for _, word := range strings.Split(text, " ") {
+		if current != "" && len(current) + 1 + len(word) > width {
+			lines = append(lines, current)
+			current = word
+		} else if current == "" && len(lines) == 0 {
+			current = word
+		} else {
+			current = current + " " + word
+		}
+	}
+
+	if len(current) < 2 && len(lines) > 0 {
+		lines[len(lines) - 1] = lines[len(lines) - 1] + " " + current
+	} else if current != "" {
+		lines = append(lines, current)
+	}
+
+	return lines
+}
+
+func formatNumber (f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func padRight (s string, n int) string {
+	for len(s) < n {
+		s = s + " "
+	}
+
+	return s
+}
+
+func padLeft (s string, n int) string {
+	for len(s) < n {
+		s = " " + s
+	}
+
+	return s
+}
+
+// s with a * after it when flagged
+func flagged (s string, flag bool) string {
+	if flag {
+		return s + "*"
+	}
+
+	return s
+}
+
+// keeps s from running into the next column
+func cut (s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+
+	return s
+}
@@ -0,0 +1,83 @@
+package datasheet
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestWriterRoundTrip (t *testing.T) {
+	for name := range corpus {
+		sheets := readAll(t, name)
+
+		var out bytes.Buffer
+		w := NewWriter(&out)
+
+		for _, sheet := range sheets {
+			if err := w.Write(sheet); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		r := NewReader(&out)
+
+		for i, want := range sheets {
+			got, err := r.Read()
+
+			if err != nil {
+				t.Fatalf("%s: sheet %d: %v", name, i, err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: %s changed after writing it\ngot  %+v\nwant %+v", name, want.Id, got, want)
+			}
+		}
+
+		if _, err := r.Read(); err != io.EOF {
+			t.Errorf("%s: got %v after the last sheet, want io.EOF", name, err)
+		}
+	}
+}
+
+func TestWriterProgram (t *testing.T) {
+	sheet := readAll(t, "testdata/first_order.txt")[0]
+	sheet.Program = "datasheet95"
+	sheet.ProgramVersion = "8.12.5.3"
+
+	var out bytes.Buffer
+	w := NewWriter(&out)
+	w.Write(sheet)
+	w.Flush()
+
+	r := NewReader(&out)
+	got, err := r.Read()
+
+	if err != nil || !reflect.DeepEqual(got, sheet) {
+		t.Errorf("the program did not make it through writing: %q %q %v", got.Program, got.ProgramVersion, err)
+	}
+}
+
+func TestWrapText (t *testing.T) {
+	tests := []struct {
+		text string
+		width int
+		want []string
+	}{
+		{"", 10, []string{}},
+		{"ONE TWO THREE", 20, []string{"ONE TWO THREE"}},
+		{"ONE TWO THREE", 8, []string{"ONE TWO", "THREE"}},
+		{"ONE TWO A", 7, []string{"ONE TWO A"}},
+		{"SUPERCALIFRAGILISTIC IS", 8, []string{"SUPERCALIFRAGILISTIC", "IS"}},
+	}
+
+	for _, tt := range tests {
+		if got := wrapText(tt.text, tt.width); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("wrapText(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+// Package diff compares two releases of a datasheet archive. the sheets are matched by pid
+// and compared field by field on their json, so every change has the path of the field it
+// is in, ex: history[3] or position.lat, and can be written as text or as a json patch.
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/carterharrison/dsdata/datasheet"
+)
+
+// Status is what happened to a station between the releases
+type Status string
+
+const (
+	Added Status = "added"
+	Removed Status = "removed"
+	Modified Status = "modified"
+)
+
+// Change is one field of a sheet that changed
+type Change struct {
+	// add, remove or replace, as in a json patch
+	Op string `json:"op"`
+
+	// the json path of the field like the keys of DataSheet.Provenance, ex: history[3]
+	Path string `json:"path"`
+
+	// the json pointer of the field in the sheet, ex: /history/3
+	Pointer string `json:"pointer"`
+
+	// the json of the field before and after, nil when it was added or removed
+	Old interface{} `json:"old,omitempty"`
+	New interface{} `json:"new,omitempty"`
+}
+
+// Station is a station that was added, removed or modified
+type Station struct {
+	Id string `json:"id"`
+	Designation string `json:"designation"`
+	Status Status `json:"status"`
+
+	// the fields that changed, only for a modified station
+	Changes []Change `json:"changes,omitempty"`
+
+	// the whole sheet, only for an added or removed station
+	Sheet *datasheet.DataSheet `json:"sheet,omitempty"`
+}
+
+// Operation is an operation of a json patch (rfc 6902)
+type Operation struct {
+	Op string `json:"op"`
+	Path string `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Archives compares the sheets of two releases and returns the stations that changed by pid.
+// sheets without a pid are left out, when a pid is in a release twice the last sheet is used
+func Archives (old []datasheet.DataSheet, new []datasheet.DataSheet) ([]Station, error) {
+	oldSheets := byId(old)
+	newSheets := byId(new)
+	stations := make([]Station, 0)
+
+	for id, sheet := range oldSheets {
+		if _, ok := newSheets[id]; !ok {
+			stations = append(stations, Station{Id: id, Designation: designation(sheet), Status: Removed, Sheet: sheet})
+		}
+	}
+
+	for id, sheet := range newSheets {
+		before, ok := oldSheets[id]
+
+		if !ok {
+			stations = append(stations, Station{Id: id, Designation: designation(sheet), Status: Added, Sheet: sheet})
+			continue
+		}
+
+		changes, err := Sheets(*before, *sheet)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+
+		if len(changes) > 0 {
+			stations = append(stations, Station{Id: id, Designation: designation(sheet), Status: Modified, Changes: changes})
+		}
+	}
+
+	sort.Slice(stations, func (i, j int) bool {
+		return stations[i].Id < stations[j].Id
+	})
+
+	return stations, nil
+}
+
+// Sheets is the changes from one sheet to the other. the provenance, member and program are
+// not compared, they change whenever the lines before the sheet, the bundle it is in or the
+// program that retrieved it do
+func Sheets (old datasheet.DataSheet, new datasheet.DataSheet) ([]Change, error) {
+	old.Provenance = nil
+	new.Provenance = nil
+	old.Member = ""
+	new.Member = ""
+	old.Program, old.ProgramVersion = "", ""
+	new.Program, new.ProgramVersion = "", ""
+
+	a, err := toJSON(old)
+
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := toJSON(new)
+
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]Change, 0)
+	compare(a, b, "", "", &changes)
+
+	return changes, nil
+}
+
+// Patch is the json patch that turns the old archive into the new one, with the archive as an
+// object of the sheets by pid, ex: {"HV4612": {...}}
+func Patch (stations []Station) []Operation {
+	ops := make([]Operation, 0)
+
+	for _, station := range stations {
+		root := "/" + escapePointer(station.Id)
+
+		switch station.Status {
+		case Added: ops = append(ops, Operation{Op: "add", Path: root, Value: station.Sheet})
+		case Removed: ops = append(ops, Operation{Op: "remove", Path: root})
+		case Modified:
+			for _, change := range station.Changes {
+				op := Operation{Op: change.Op, Path: root + change.Pointer, Value: change.New}
+
+				// a field set to null still needs its value
+				if op.Value == nil && op.Op != "remove" {
+					op.Value = json.RawMessage("null")
+				}
+
+				ops = append(ops, op)
+			}
+		}
+	}
+
+	return ops
+}
+
+// WriteText writes the stations a line each, + added, - removed and ~ modified, with the
+// changes of a modified one under it
+func WriteText (w io.Writer, stations []Station) error {
+	counts := make(map[Status]int)
+
+	for _, station := range stations {
+		counts[station.Status]++
+
+		mark := map[Status]string{Added: "+", Removed: "-", Modified: "~"}[station.Status]
+
+		if _, err := fmt.Fprintf(w, "%s %s %s\n", mark, station.Id, station.Designation); err != nil {
+			return err
+		}
+
+		for _, change := range station.Changes {
+			var line string
+
+			switch change.Op {
+			case "add": line = fmt.Sprintf("    + %s %s", change.Path, compact(change.New))
+			case "remove": line = fmt.Sprintf("    - %s %s", change.Path, compact(change.Old))
+			default: line = fmt.Sprintf("    ~ %s %s -> %s", change.Path, compact(change.Old), compact(change.New))
+			}
+
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "%d added, %d removed, %d modified\n", counts[Added], counts[Removed], counts[Modified])
+	return err
+}
+
+// compares two json values and adds the changes to turn a into b, the paths are of a
+func compare (a interface{}, b interface{}, path string, pointer string, changes *[]Change) {
+	if reflect.DeepEqual(a, b) {
+		return
+	}
+
+	switch a := a.(type) {
+	case map[string]interface{}:
+		if b, ok := b.(map[string]interface{}); ok {
+			compareObjects(a, b, path, pointer, changes)
+			return
+		}
+	case []interface{}:
+		if b, ok := b.([]interface{}); ok {
+			compareArrays(a, b, path, pointer, changes)
+			return
+		}
+	}
+
+	*changes = append(*changes, Change{Op: "replace", Path: path, Pointer: pointer, Old: a, New: b})
+}
+
+func compareObjects (a map[string]interface{}, b map[string]interface{}, path string, pointer string, changes *[]Change) {
+	keys := make([]string, 0, len(a) + len(b))
+
+	for key := range a {
+		keys = append(keys, key)
+	}
+
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		childPath := key
+
+		if path != "" {
+			childPath = path + "." + key
+		}
+
+		childPointer := pointer + "/" + escapePointer(key)
+		before, inA := a[key]
+		after, inB := b[key]
+
+		switch {
+		case !inB: *changes = append(*changes, Change{Op: "remove", Path: childPath, Pointer: childPointer, Old: before})
+		case !inA: *changes = append(*changes, Change{Op: "add", Path: childPath, Pointer: childPointer, New: after})
+		default: compare(before, after, childPath, childPointer, changes)
+		}
+	}
+}
+
+// the items the arrays start and end with are the same, the ones in between are compared by
+// index and the rest are added or removed. rows put on the end of the history or at the top
+// of the superseded control come out as adds. the changes can be applied in order
+func compareArrays (a []interface{}, b []interface{}, path string, pointer string, changes *[]Change) {
+	prefix := 0
+
+	for prefix < len(a) && prefix < len(b) && reflect.DeepEqual(a[prefix], b[prefix]) {
+		prefix++
+	}
+
+	suffix := 0
+
+	for suffix < len(a) - prefix && suffix < len(b) - prefix && reflect.DeepEqual(a[len(a) - 1 - suffix], b[len(b) - 1 - suffix]) {
+		suffix++
+	}
+
+	middleA := len(a) - prefix - suffix
+	middleB := len(b) - prefix - suffix
+	both := middleA
+
+	if middleB < both {
+		both = middleB
+	}
+
+	item := func (i int) (string, string) {
+		return path + "[" + strconv.Itoa(i) + "]", pointer + "/" + strconv.Itoa(i)
+	}
+
+	for i := prefix; i < prefix + both; i++ {
+		p, ptr := item(i)
+		compare(a[i], b[i], p, ptr, changes)
+	}
+
+	// removed from the last so the indexes before stay put
+	for i := prefix + middleA - 1; i >= prefix + both; i-- {
+		p, ptr := item(i)
+		*changes = append(*changes, Change{Op: "remove", Path: p, Pointer: ptr, Old: a[i]})
+	}
+
+	for i := prefix + both; i < prefix + middleB; i++ {
+		p, ptr := item(i)
+		*changes = append(*changes, Change{Op: "add", Path: p, Pointer: ptr, New: b[i]})
+	}
+}
+
+func byId (sheets []datasheet.DataSheet) map[string]*datasheet.DataSheet {
+	out := make(map[string]*datasheet.DataSheet, len(sheets))
+
+	for i := range sheets {
+		if sheets[i].Id != "" {
+			out[sheets[i].Id] = &sheets[i]
+		}
+	}
+
+	return out
+}
+
+func designation (sheet *datasheet.DataSheet) string {
+	return sheet.BasicMetadata["DESIGNATION"]
+}
+
+// the sheet as decoded json, so it is compared the way it is written
+func toJSON (sheet datasheet.DataSheet) (interface{}, error) {
+	data, err := json.Marshal(sheet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	err = json.Unmarshal(data, &value)
+
+	return value, err
+}
+
+// ~ => ~0 and / => ~1, ex: STATE/COUNTY => STATE~1COUNTY
+func escapePointer (token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func compact (value interface{}) string {
+	data, err := json.Marshal(value)
+
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(data)
+}
@@ -0,0 +1,280 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/carterharrison/dsdata/datasheet"
+)
+
+func testSheet (id string, designation string) datasheet.DataSheet {
+	sheet := datasheet.DataSheet{}
+	sheet.Init()
+	sheet.Id = id
+	sheet.BasicMetadata["DESIGNATION"] = designation
+	sheet.BasicMetadata["STATE/COUNTY"] = "CA/SAN DIEGO"
+	sheet.Position = &datasheet.Position{Lat: 32.718198, Lon: -117.138927}
+	sheet.Accuracy.Network = []datasheet.NetworkAccuracy{{Horiz: 1.21, Ellip: 2.04}}
+	sheet.History = []datasheet.History{
+		{Date: "1934", Condition: "MONUMENTED", By: "CGS"},
+		{Date: "20080312", Condition: "GOOD", By: "SDCO"},
+	}
+
+	return sheet
+}
+
+// the old and new release, HV4612 is modified, KV0001 removed, KV0002 added and DF4370 the same
+func testReleases () ([]datasheet.DataSheet, []datasheet.DataSheet) {
+	old := []datasheet.DataSheet{testSheet("HV4612", "MOUNT HOPE"), testSheet("KV0001", "N 35"), testSheet("DF4370", "SIO5")}
+
+	modified := testSheet("HV4612", "MOUNT HOPE")
+	modified.BasicMetadata["STATE/COUNTY"] = "CA/SAN DIEGO COUNTY"
+	modified.Position.Lat = 32.718199
+	modified.Accuracy.Network[0].Horiz = 0.98
+	modified.History = append(modified.History, datasheet.History{Date: "20150101", Condition: "NOT FOUND", By: "SDCO"})
+	modified.StationRecoveries = []datasheet.StationRecovery{{Date: "2015", Description: "NOT FOUND"}}
+	modified.OldSurveyControl = []datasheet.Survey{{Item: "NAD 83(1986) POSITION", Value: "32 43 05.5", By: "ADJUSTED"}}
+	modified.Provenance = map[string]datasheet.Provenance{"id": {StartLine: 10}}
+
+	new := []datasheet.DataSheet{testSheet("DF4370", "SIO5"), modified, testSheet("KV0002", "P 35")}
+
+	return old, new
+}
+
+func TestArchives (t *testing.T) {
+	old, new := testReleases()
+	stations, err := Archives(old, new)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stations) != 3 {
+		t.Fatalf("got %d stations, want 3", len(stations))
+	}
+
+	statuses := []Status{stations[0].Status, stations[1].Status, stations[2].Status}
+
+	if !reflect.DeepEqual(statuses, []Status{Modified, Removed, Added}) || stations[0].Id != "HV4612" {
+		t.Errorf("got %v", statuses)
+	}
+
+	paths := make([]string, 0)
+
+	for _, change := range stations[0].Changes {
+		paths = append(paths, change.Op + " " + change.Path)
+	}
+
+	want := []string{
+		"replace accuracy.network[0].horiz",
+		"add history[2]",
+		"replace metadata.STATE/COUNTY",
+		"add oldSurveys[0]",
+		"replace position.lat",
+		"add stationRecoveries[0]",
+	}
+
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("got %v, want %v", paths, want)
+	}
+}
+
+func TestSheetsProgram (t *testing.T) {
+	old := testSheet("HV4612", "MOUNT HOPE")
+	old.Program, old.ProgramVersion = "datasheet95", "8.12.5.3"
+
+	new := testSheet("HV4612", "MOUNT HOPE")
+	new.Program, new.ProgramVersion = "datasheet95", "8.12.5.15"
+
+	changes, err := Sheets(old, new)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(changes) != 0 {
+		t.Errorf("got %v for a new program version", changes)
+	}
+}
+
+func TestCompareArrays (t *testing.T) {
+	items := func (s string) []interface{} {
+		out := make([]interface{}, 0)
+
+		for _, c := range strings.Split(s, "") {
+			out = append(out, c)
+		}
+
+		return out
+	}
+
+	tests := []struct {
+		a string
+		b string
+	}{
+		{"abc", "abcd"},
+		{"abc", "xabc"},
+		{"abcd", "ad"},
+		{"abcd", "axyzd"},
+		{"abc", ""},
+		{"", "abc"},
+		{"abcdef", "abXYef"},
+	}
+
+	for _, tt := range tests {
+		changes := make([]Change, 0)
+		compareArrays(items(tt.a), items(tt.b), "x", "", &changes)
+
+		var doc interface{} = items(tt.a)
+
+		for _, change := range changes {
+			doc = apply(t, doc, Operation{Op: change.Op, Path: change.Pointer, Value: change.New})
+		}
+
+		if !reflect.DeepEqual(doc, items(tt.b)) {
+			t.Errorf("%s => %s: the changes %v give %v", tt.a, tt.b, changes, doc)
+		}
+	}
+}
+
+func TestPatch (t *testing.T) {
+	old, new := testReleases()
+	stations, err := Archives(old, new)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the patch goes through json like it would be written out
+	data, err := json.Marshal(Patch(stations))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ops []Operation
+
+	if err := json.Unmarshal(data, &ops); err != nil {
+		t.Fatal(err)
+	}
+
+	doc := archiveJSON(t, old)
+
+	for _, op := range ops {
+		doc = apply(t, doc, op)
+	}
+
+	if want := archiveJSON(t, new); !reflect.DeepEqual(doc, want) {
+		t.Errorf("the patched archive is not the new one\ngot  %v\nwant %v", doc, want)
+	}
+}
+
+func TestWriteText (t *testing.T) {
+	old, new := testReleases()
+	stations, _ := Archives(old, new)
+
+	var out bytes.Buffer
+
+	if err := WriteText(&out, stations); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{
+		"~ HV4612 MOUNT HOPE\n",
+		"    + history[2] {\"by\":\"SDCO\",\"condition\":\"NOT FOUND\",\"date\":\"20150101\"}\n",
+		"    ~ position.lat 32.718198 -> 32.718199\n",
+		"- KV0001 N 35\n",
+		"+ KV0002 P 35\n",
+		"1 added, 1 removed, 1 modified\n",
+	} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("the text does not have %q:\n%s", line, out.String())
+		}
+	}
+}
+
+// the archive as an object of the sheets by pid, without provenance
+func archiveJSON (t *testing.T, sheets []datasheet.DataSheet) interface{} {
+	t.Helper()
+	archive := make(map[string]datasheet.DataSheet)
+
+	for _, sheet := range sheets {
+		sheet.Provenance = nil
+		archive[sheet.Id] = sheet
+	}
+
+	data, err := json.Marshal(archive)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc interface{}
+	json.Unmarshal(data, &doc)
+
+	return doc
+}
+
+// applies an add, remove or replace operation to the decoded json
+func apply (t *testing.T, doc interface{}, op Operation) interface{} {
+	t.Helper()
+
+	if op.Path == "" {
+		return jsonValue(t, op.Value)
+	}
+
+	tokens := strings.Split(op.Path[1:], "/")
+	token := strings.ReplaceAll(strings.ReplaceAll(tokens[0], "~1", "/"), "~0", "~")
+	rest := ""
+
+	if len(tokens) > 1 {
+		rest = "/" + strings.Join(tokens[1:], "/")
+	}
+
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		switch {
+		case rest != "": node[token] = apply(t, node[token], Operation{Op: op.Op, Path: rest, Value: op.Value})
+		case op.Op == "remove": delete(node, token)
+		default: node[token] = jsonValue(t, op.Value)
+		}
+
+		return node
+	case []interface{}:
+		i, err := strconv.Atoi(token)
+
+		if err != nil || i > len(node) {
+			t.Fatalf("bad index %s of %v", op.Path, node)
+		}
+
+		switch {
+		case rest != "": node[i] = apply(t, node[i], Operation{Op: op.Op, Path: rest, Value: op.Value})
+		case op.Op == "remove": node = append(node[:i], node[i + 1:]...)
+		case op.Op == "add": node = append(node[:i], append([]interface{}{jsonValue(t, op.Value)}, node[i:]...)...)
+		default: node[i] = jsonValue(t, op.Value)
+		}
+
+		return node
+	}
+
+	t.Fatalf("%s goes into %v", op.Path, doc)
+	return nil
+}
+
+// the value as decoded json
+func jsonValue (t *testing.T, value interface{}) interface{} {
+	data, err := json.Marshal(value)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out interface{}
+	json.Unmarshal(data, &out)
+
+	return out
+}
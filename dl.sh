@@ -1,3 +0,0 @@
-#!/bin/bash
-
-wget -P ./temp -r ftp://ftp.ngs.noaa.gov/pub/DS_ARCHIVE/DataSheets/
\ No newline at end of file
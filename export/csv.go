@@ -0,0 +1,377 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/carterharrison/dsdata/datasheet"
+)
+
+// Pick is how a repeated structure of a sheet, like the history, is turned into rows
+type Pick int
+
+const (
+	// one row with the most recent item, for state plane coordinates the last one listed
+	PickLatest Pick = iota
+
+	// one row with the first item listed
+	PickFirst
+
+	// a row for every item
+	Explode
+)
+
+// ParsePick reads latest, first or explode
+func ParsePick (s string) (Pick, error) {
+	switch s {
+	case "latest": return PickLatest, nil
+	case "first": return PickFirst, nil
+	case "explode": return Explode, nil
+	}
+
+	return 0, fmt.Errorf("unknown pick %q, want latest, first or explode", s)
+}
+
+// a sheet with the history and state plane coordinates picked for one row
+type row struct {
+	sheet *datasheet.DataSheet
+	history *datasheet.History
+	spc *datasheet.StatePlaneCoordinates
+}
+
+// the columns that can be exported, the names are stable
+var csvColumns = map[string]func (r row) string{
+	// basic metadata
+	"id": func (r row) string { return r.sheet.Id },
+	"designation": metadataColumn("DESIGNATION"),
+	"state_county": metadataColumn("STATE/COUNTY"),
+	"country": metadataColumn("COUNTRY"),
+	"usgs_quad": metadataColumn("USGS QUAD"),
+
+	// current survey control
+	"lat": func (r row) string {
+		if r.sheet.Position == nil { return "" }
+		return formatFloat(r.sheet.Position.Lat)
+	},
+	"lon": func (r row) string {
+		if r.sheet.Position == nil { return "" }
+		return formatFloat(r.sheet.Position.Lon)
+	},
+	"datum": func (r row) string {
+		if r.sheet.Position == nil { return "" }
+		if r.sheet.Position.Realization == "" { return r.sheet.Position.Datum }
+		return r.sheet.Position.Datum + "(" + r.sheet.Position.Realization + ")"
+	},
+	"epoch": func (r row) string {
+		if r.sheet.Position == nil || r.sheet.Position.Epoch == 0 { return "" }
+		return formatFloat(r.sheet.Position.Epoch)
+	},
+	"position_source": func (r row) string {
+		if r.sheet.Position == nil { return "" }
+		return r.sheet.Position.Source
+	},
+	"ellip_h": func (r row) string {
+		if r.sheet.EllipsoidHeight == nil { return "" }
+		return formatFloat(r.sheet.EllipsoidHeight.Height)
+	},
+	"ortho_h": func (r row) string {
+		if r.sheet.OrthometricHeight == nil { return "" }
+		return formatFloat(r.sheet.OrthometricHeight.Height)
+	},
+	"ortho_datum": func (r row) string {
+		if r.sheet.OrthometricHeight == nil { return "" }
+		return r.sheet.OrthometricHeight.Datum
+	},
+	"navd88": func (r row) string {
+		if r.sheet.OrthometricHeight == nil || r.sheet.OrthometricHeight.Datum != "NAVD 88" { return "" }
+		return formatFloat(r.sheet.OrthometricHeight.Height)
+	},
+	"geoid_h": func (r row) string {
+		if r.sheet.GeoidHeight == nil { return "" }
+		return formatFloat(r.sheet.GeoidHeight.Height)
+	},
+	"geoid_model": func (r row) string {
+		if r.sheet.GeoidHeight == nil { return "" }
+		return r.sheet.GeoidHeight.Model
+	},
+	"x": func (r row) string {
+		if r.sheet.XYZ == nil { return "" }
+		return formatFloat(r.sheet.XYZ.X)
+	},
+	"y": func (r row) string {
+		if r.sheet.XYZ == nil { return "" }
+		return formatFloat(r.sheet.XYZ.Y)
+	},
+	"z": func (r row) string {
+		if r.sheet.XYZ == nil { return "" }
+		return formatFloat(r.sheet.XYZ.Z)
+	},
+
+	// accuracy
+	"horz_order": func (r row) string { return first(r.sheet.Accuracy.HorzOrder) },
+	"ellp_order": func (r row) string { return first(r.sheet.Accuracy.EllpOrder) },
+	"vert_order": func (r row) string { return first(r.sheet.Accuracy.VertOrder) },
+	"network_horiz": func (r row) string {
+		if len(r.sheet.Accuracy.Network) == 0 { return "" }
+		return formatFloat(r.sheet.Accuracy.Network[0].Horiz)
+	},
+	"network_ellip": func (r row) string {
+		if len(r.sheet.Accuracy.Network) == 0 { return "" }
+		return formatFloat(r.sheet.Accuracy.Network[0].Ellip)
+	},
+
+	// projections
+	"spatial_address": func (r row) string { return r.sheet.SpatialAddress },
+	"spc_north": func (r row) string {
+		if r.spc == nil { return "" }
+		return formatFloat(r.spc.North)
+	},
+	"spc_east": func (r row) string {
+		if r.spc == nil { return "" }
+		return formatFloat(r.spc.East)
+	},
+	"spc_units": func (r row) string {
+		if r.spc == nil { return "" }
+		return r.spc.Units
+	},
+	"spc_system": func (r row) string {
+		if r.spc == nil { return "" }
+		return r.spc.System
+	},
+	"spc_zone": func (r row) string {
+		if r.spc == nil { return "" }
+		return r.spc.Zone
+	},
+	"spc_scale": func (r row) string {
+		if r.spc == nil || r.spc.Scale == 0 { return "" }
+		return formatFloat(r.spc.Scale)
+	},
+	"spc_convergence": func (r row) string {
+		if r.spc == nil || len(r.spc.Converg) == 0 { return "" }
+		return formatFloat(r.spc.Convergence)
+	},
+
+	// monumentation
+	"marker": monumentationColumn("_MARKER"),
+	"setting": monumentationColumn("_SETTING"),
+	"stamping": monumentationColumn("_STAMPING"),
+	"magnetic": monumentationColumn("_MAGNETIC"),
+	"stability": monumentationColumn("_STABILITY"),
+
+	// history, the latest row no matter how the history is picked
+	"last_condition": func (r row) string {
+		history, _ := r.sheet.LatestHistory()
+		return history.Condition
+	},
+	"last_condition_date": func (r row) string {
+		history, _ := r.sheet.LatestHistory()
+		return history.Date
+	},
+	"last_recovered": func (r row) string {
+		return lastRecovered(r.sheet)
+	},
+
+	// history, the row picked by the writer's History
+	"history_date": func (r row) string {
+		if r.history == nil { return "" }
+		return r.history.Date
+	},
+	"history_condition": func (r row) string {
+		if r.history == nil { return "" }
+		return r.history.Condition
+	},
+	"history_by": func (r row) string {
+		if r.history == nil { return "" }
+		return r.history.By
+	},
+}
+
+// CSVColumns are the names of all the columns a CSVWriter knows, sorted
+func CSVColumns () []string {
+	names := make([]string, 0, len(csvColumns))
+
+	for name := range csvColumns {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+var DefaultCSVColumns = []string{"id", "designation", "lat", "lon", "ellip_h", "navd88", "marker", "setting", "last_condition", "last_recovered"}
+
+// CSVWriter writes a header and then a row for each sheet, or more than one row when
+// the history or state plane coordinates are exploded
+type CSVWriter struct {
+	// which history row goes with the history_* columns, PickLatest by default
+	History Pick
+
+	// which state plane coordinates go with the spc_* columns, PickFirst by default
+	SPC Pick
+
+	csv *csv.Writer
+	columns []string
+	wroteHeader bool
+}
+
+// comma is the separator, ',' for csv or '\t' for tsv. columns nil for DefaultCSVColumns
+func NewCSVWriter (w io.Writer, columns []string, comma rune) (*CSVWriter, error) {
+	if columns == nil {
+		columns = DefaultCSVColumns
+	}
+
+	for _, name := range columns {
+		if _, ok := csvColumns[name]; !ok {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = comma
+
+	return &CSVWriter{
+		History: PickLatest,
+		SPC: PickFirst,
+		csv: writer,
+		columns: columns,
+	}, nil
+}
+
+func (writer *CSVWriter) Write (sheet datasheet.DataSheet) error {
+	if err := writer.header(); err != nil {
+		return err
+	}
+
+	histories := pickHistory(&sheet, writer.History)
+	spcs := pickSPC(&sheet, writer.SPC)
+
+	for _, history := range histories {
+		for _, spc := range spcs {
+			r := row{sheet: &sheet, history: history, spc: spc}
+			record := make([]string, len(writer.columns))
+
+			for i, name := range writer.columns {
+				record[i] = csvColumns[name](r)
+			}
+
+			if err := writer.csv.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (writer *CSVWriter) Close () error {
+	if err := writer.header(); err != nil {
+		return err
+	}
+
+	writer.csv.Flush()
+	return writer.csv.Error()
+}
+
+func (writer *CSVWriter) header () error {
+	if writer.wroteHeader {
+		return nil
+	}
+
+	writer.wroteHeader = true
+	return writer.csv.Write(writer.columns)
+}
+
+// the history rows to write, a nil row when there is no history so the sheet still gets a row
+func pickHistory (sheet *datasheet.DataSheet, pick Pick) []*datasheet.History {
+	if len(sheet.History) == 0 {
+		return []*datasheet.History{nil}
+	}
+
+	switch pick {
+	case PickFirst:
+		return []*datasheet.History{&sheet.History[0]}
+	case Explode:
+		rows := make([]*datasheet.History, len(sheet.History))
+
+		for i := range sheet.History {
+			rows[i] = &sheet.History[i]
+		}
+
+		return rows
+	}
+
+	latest, _ := sheet.LatestHistory()
+	return []*datasheet.History{&latest}
+}
+
+func pickSPC (sheet *datasheet.DataSheet, pick Pick) []*datasheet.StatePlaneCoordinates {
+	spcs := sheet.StatePlaneCoordinates
+
+	if len(spcs) == 0 {
+		return []*datasheet.StatePlaneCoordinates{nil}
+	}
+
+	switch pick {
+	case PickLatest:
+		return []*datasheet.StatePlaneCoordinates{&spcs[len(spcs) - 1]}
+	case Explode:
+		rows := make([]*datasheet.StatePlaneCoordinates, len(spcs))
+
+		for i := range spcs {
+			rows[i] = &spcs[i]
+		}
+
+		return rows
+	}
+
+	return []*datasheet.StatePlaneCoordinates{&spcs[0]}
+}
+
+// the conditions of a history row where the mark was found
+var recoveredConditions = map[string]bool{
+	"GOOD": true,
+	"POOR": true,
+	"SEE DESCRIPTION": true,
+}
+
+// date of the latest history row where the mark was found, not set, lost or destroyed
+func lastRecovered (sheet *datasheet.DataSheet) string {
+	recoveries := datasheet.DataSheet{}
+
+	for _, history := range sheet.History {
+		if recoveredConditions[strings.ToUpper(strings.TrimSpace(history.Condition))] {
+			recoveries.History = append(recoveries.History, history)
+		}
+	}
+
+	history, _ := recoveries.LatestHistory()
+	return history.Date
+}
+
+func metadataColumn (key string) func (r row) string {
+	return func (r row) string {
+		return r.sheet.BasicMetadata[key]
+	}
+}
+
+func monumentationColumn (key string) func (r row) string {
+	return func (r row) string {
+		return r.sheet.Monumentation[key]
+	}
+}
+
+func first (values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+func formatFloat (f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
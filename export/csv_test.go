@@ -0,0 +1,126 @@
+package export
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/carterharrison/dsdata/datasheet"
+)
+
+func TestCSVWriter (t *testing.T) {
+	sheet := testSheet("HV4612")
+	sheet.StatePlaneCoordinates = []datasheet.StatePlaneCoordinates{
+		{North: 1840946.512, East: 1931213.88, Units: "MT", System: "SPC", Zone: "CA 6"},
+		{North: 3625992.401, East: 487030.542, Units: "MT", System: "UTM", Zone: "11", Scale: 0.99960206, Factor: math.Copysign(0, -1), Converg: []float64{4, 25.8}, Convergence: -0.0738333},
+	}
+
+	tests := []struct {
+		name string
+		columns []string
+		comma rune
+		history Pick
+		spc Pick
+		want string
+	}{
+		{
+			name: "default columns",
+			comma: ',',
+			want: "id,designation,lat,lon,ellip_h,navd88,marker,setting,last_condition,last_recovered\n" +
+				"HV4612,MOUNT HOPE,32.718198,-117.138927,97.632,,DD = SURVEY DISK,,GOOD,20080312\n",
+		},
+		{
+			name: "tsv with the first history",
+			columns: []string{"id", "history_date", "history_condition"},
+			comma: '\t',
+			history: PickFirst,
+			want: "id\thistory_date\thistory_condition\nHV4612\t20080312\tGOOD\n",
+		},
+		{
+			name: "exploded history",
+			columns: []string{"id", "history_date", "spc_north"},
+			comma: ',',
+			history: Explode,
+			spc: PickLatest,
+			want: "id,history_date,spc_north\nHV4612,20080312,3625992.401\nHV4612,1934,3625992.401\n",
+		},
+		{
+			name: "exploded spc",
+			columns: []string{"id", "spc_east"},
+			comma: ',',
+			spc: Explode,
+			want: "id,spc_east\nHV4612,1931213.88\nHV4612,487030.542\n",
+		},
+		{
+			name: "spc zone and convergence",
+			columns: []string{"spc_system", "spc_zone", "spc_scale", "spc_convergence"},
+			comma: ',',
+			spc: PickLatest,
+			want: "spc_system,spc_zone,spc_scale,spc_convergence\nUTM,11,0.99960206,-0.0738333\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func (t *testing.T) {
+			var out bytes.Buffer
+			w, err := NewCSVWriter(&out, tt.columns, tt.comma)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			w.History = tt.history
+			w.SPC = tt.spc
+
+			if err := w.Write(sheet); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if out.String() != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVWriterRecovered (t *testing.T) {
+	sheet := testSheet("KV0002")
+	sheet.Position.Datum = "NAD 83"
+	sheet.History = []datasheet.History{
+		{Date: "1934", Condition: "MONUMENTED"},
+		{Date: "1959", Condition: "POOR"},
+		{Date: "20080312", Condition: "MARK NOT FOUND"},
+		{Date: "20120812", Condition: "DESTROYED"},
+	}
+
+	var out bytes.Buffer
+	w, _ := NewCSVWriter(&out, []string{"datum", "last_condition", "last_recovered"}, ',')
+	w.Write(sheet)
+
+	sheet.Position.Realization = "2011"
+	sheet.History = sheet.History[:1]
+	w.Write(sheet)
+	w.Close()
+
+	if want := "datum,last_condition,last_recovered\nNAD 83,DESTROYED,1959\nNAD 83(2011),MONUMENTED,\n"; out.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestCSVWriterUnknownColumn (t *testing.T) {
+	if _, err := NewCSVWriter(&bytes.Buffer{}, []string{"id", "DESIGNATION"}, ','); err == nil {
+		t.Error("want an error for an unknown column")
+	}
+}
+
+func TestCSVColumnsAreKnown (t *testing.T) {
+	for _, name := range DefaultCSVColumns {
+		if _, ok := csvColumns[name]; !ok {
+			t.Errorf("default column %s is not a column", name)
+		}
+	}
+}
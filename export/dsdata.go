@@ -0,0 +1,26 @@
+package export
+
+import (
+	"io"
+
+	"github.com/carterharrison/dsdata/datasheet"
+)
+
+// DataSheetWriter writes the sheets back out in the dsdata text layout
+type DataSheetWriter struct {
+	writer *datasheet.Writer
+}
+
+func NewDataSheetWriter (w io.Writer) *DataSheetWriter {
+	return &DataSheetWriter{
+		writer: datasheet.NewWriter(w),
+	}
+}
+
+func (writer *DataSheetWriter) Write (sheet datasheet.DataSheet) error {
+	return writer.writer.Write(sheet)
+}
+
+func (writer *DataSheetWriter) Close () error {
+	return writer.writer.Flush()
+}
@@ -0,0 +1,38 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/carterharrison/dsdata/datasheet"
+)
+
+func TestDataSheetWriter (t *testing.T) {
+	var out bytes.Buffer
+	w := NewDataSheetWriter(&out)
+
+	sheet := testSheet("HV4612")
+
+	if err := w.Write(sheet); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := datasheet.NewReader(&out)
+	got, err := r.Read()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Id != sheet.Id || got.BasicMetadata["DESIGNATION"] != sheet.BasicMetadata["DESIGNATION"] {
+		t.Errorf("got %s %q, want %s %q", got.Id, got.BasicMetadata["DESIGNATION"], sheet.Id, sheet.BasicMetadata["DESIGNATION"])
+	}
+
+	if len(got.History) != len(sheet.History) {
+		t.Errorf("got %d history rows, want %d", len(got.History), len(sheet.History))
+	}
+}
@@ -0,0 +1,16 @@
+// Package export writes parsed datasheets in formats other tools can read.
+// Every writer streams, a sheet is written as soon as it is given so a whole
+// state archive never has to be held in memory.
+package export
+
+import (
+	"github.com/carterharrison/dsdata/datasheet"
+)
+
+type Writer interface {
+	// writes one sheet
+	Write (sheet datasheet.DataSheet) error
+
+	// finishes the output and flushes it, the underlying io.Writer is not closed
+	Close () error
+}
@@ -0,0 +1,158 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/carterharrison/dsdata/datasheet"
+)
+
+// names of the properties that can be put on a feature
+var (
+	// the pid
+	IdProperty = "id"
+
+	// condition and date of the latest history row
+	LastConditionProperty = "lastCondition"
+	LastConditionDateProperty = "lastConditionDate"
+
+	// prefix for a key of the basic metadata, ex: metadata.DESIGNATION
+	MetadataPrefix = "metadata."
+
+	// prefix for a key of the monumentation, ex: monumentation._MARKER
+	MonumentationPrefix = "monumentation."
+
+	DefaultGeoJSONProperties = []string{IdProperty, "metadata.DESIGNATION", "monumentation._MARKER", LastConditionProperty}
+)
+
+// GeoJSONWriter writes a FeatureCollection with a Point for each sheet at its NAD 83 position,
+// the ellipsoid height is the z when the sheet has one. sheets without a position are skipped
+type GeoJSONWriter struct {
+	buf *bufio.Writer
+	properties []string
+	count int
+}
+
+type feature struct {
+	Type string `json:"type"`
+	Id string `json:"id"`
+	Geometry geometry `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geometry struct {
+	Type string `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+// properties are the names of the properties put on each feature, nil for DefaultGeoJSONProperties
+func NewGeoJSONWriter (w io.Writer, properties []string) (*GeoJSONWriter, error) {
+	if properties == nil {
+		properties = DefaultGeoJSONProperties
+	}
+
+	for _, name := range properties {
+		if !isProperty(name) {
+			return nil, fmt.Errorf("unknown property %q", name)
+		}
+	}
+
+	return &GeoJSONWriter{
+		buf: bufio.NewWriter(w),
+		properties: properties,
+	}, nil
+}
+
+func (writer *GeoJSONWriter) Write (sheet datasheet.DataSheet) error {
+	if sheet.Position == nil {
+		return nil
+	}
+
+	coords := []float64{sheet.Position.Lon, sheet.Position.Lat}
+
+	if sheet.EllipsoidHeight != nil && sheet.EllipsoidHeight.Unit == "meters" {
+		coords = append(coords, sheet.EllipsoidHeight.Height)
+	}
+
+	f := feature{
+		Type: "Feature",
+		Id: sheet.Id,
+		Geometry: geometry{Type: "Point", Coordinates: coords},
+		Properties: make(map[string]interface{}),
+	}
+
+	for _, name := range writer.properties {
+		f.Properties[name] = propertyValue(sheet, name)
+	}
+
+	data, err := json.Marshal(f)
+
+	if err != nil {
+		return err
+	}
+
+	if writer.count == 0 {
+		writer.buf.WriteString(`{"type":"FeatureCollection","features":[` + "\n")
+	} else {
+		writer.buf.WriteString(",\n")
+	}
+
+	writer.count++
+	_, err = writer.buf.Write(data)
+	return err
+}
+
+func (writer *GeoJSONWriter) Close () error {
+	if writer.count == 0 {
+		writer.buf.WriteString(`{"type":"FeatureCollection","features":[`)
+	}
+
+	writer.buf.WriteString("\n]}\n")
+	return writer.buf.Flush()
+}
+
+// the value of the named property, nil when the sheet does not have it
+func propertyValue (sheet datasheet.DataSheet, name string) interface{} {
+	switch {
+	case name == IdProperty:
+		return sheet.Id
+
+	case name == LastConditionProperty || name == LastConditionDateProperty:
+		history, ok := sheet.LatestHistory()
+
+		if !ok {
+			return nil
+		}
+
+		if name == LastConditionProperty {
+			return history.Condition
+		}
+
+		return history.Date
+
+	case strings.HasPrefix(name, MetadataPrefix):
+		if value, ok := sheet.BasicMetadata[strings.TrimPrefix(name, MetadataPrefix)]; ok {
+			return value
+		}
+
+	case strings.HasPrefix(name, MonumentationPrefix):
+		if value, ok := sheet.Monumentation[strings.TrimPrefix(name, MonumentationPrefix)]; ok {
+			return value
+		}
+	}
+
+	return nil
+}
+
+func isProperty (name string) bool {
+	switch name {
+	case IdProperty, LastConditionProperty, LastConditionDateProperty:
+		return true
+	}
+
+	return len(name) > len(MetadataPrefix) && strings.HasPrefix(name, MetadataPrefix) ||
+		len(name) > len(MonumentationPrefix) && strings.HasPrefix(name, MonumentationPrefix)
+}
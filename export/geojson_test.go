@@ -0,0 +1,111 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/carterharrison/dsdata/datasheet"
+)
+
+func testSheet (id string) datasheet.DataSheet {
+	sheet := datasheet.DataSheet{}
+	sheet.Init()
+	sheet.Id = id
+	sheet.BasicMetadata["DESIGNATION"] = "MOUNT HOPE"
+	sheet.Monumentation["_MARKER"] = "DD = SURVEY DISK"
+	sheet.Position = &datasheet.Position{Lat: 32.718198, Lon: -117.138927}
+	sheet.EllipsoidHeight = &datasheet.EllipsoidHeight{Height: 97.632, Unit: "meters"}
+	sheet.History = []datasheet.History{
+		{Date: "20080312", Condition: "GOOD", By: "SDCO"},
+		{Date: "1934", Condition: "MONUMENTED", By: "CGS"},
+	}
+
+	return sheet
+}
+
+type featureCollection struct {
+	Type string `json:"type"`
+	Features []feature `json:"features"`
+}
+
+func TestGeoJSONWriter (t *testing.T) {
+	var out bytes.Buffer
+	w, err := NewGeoJSONWriter(&out, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	noPosition := testSheet("KV0003")
+	noPosition.Position = nil
+
+	noHeight := testSheet("KV0001")
+	noHeight.EllipsoidHeight = nil
+	noHeight.Monumentation = map[string]string{}
+
+	for _, sheet := range []datasheet.DataSheet{testSheet("HV4612"), noPosition, noHeight} {
+		if err := w.Write(sheet); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var fc featureCollection
+
+	if err := json.Unmarshal(out.Bytes(), &fc); err != nil {
+		t.Fatalf("%v\n%s", err, out.String())
+	}
+
+	if fc.Type != "FeatureCollection" || len(fc.Features) != 2 {
+		t.Fatalf("got %s with %d features, want a FeatureCollection with 2", fc.Type, len(fc.Features))
+	}
+
+	first := fc.Features[0]
+
+	if first.Id != "HV4612" || !reflect.DeepEqual(first.Geometry.Coordinates, []float64{-117.138927, 32.718198, 97.632}) {
+		t.Errorf("got %s at %v", first.Id, first.Geometry.Coordinates)
+	}
+
+	wantProps := map[string]interface{}{
+		"id": "HV4612",
+		"metadata.DESIGNATION": "MOUNT HOPE",
+		"monumentation._MARKER": "DD = SURVEY DISK",
+		"lastCondition": "GOOD",
+	}
+
+	if !reflect.DeepEqual(first.Properties, wantProps) {
+		t.Errorf("got %v, want %v", first.Properties, wantProps)
+	}
+
+	second := fc.Features[1]
+
+	if len(second.Geometry.Coordinates) != 2 || second.Properties["monumentation._MARKER"] != nil {
+		t.Errorf("got %v %v, want 2d point without a marker", second.Geometry.Coordinates, second.Properties)
+	}
+}
+
+func TestGeoJSONWriterEmpty (t *testing.T) {
+	var out bytes.Buffer
+	w, _ := NewGeoJSONWriter(&out, []string{"id"})
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var fc featureCollection
+
+	if err := json.Unmarshal(out.Bytes(), &fc); err != nil || len(fc.Features) != 0 {
+		t.Errorf("got %v %s, want an empty collection", err, out.String())
+	}
+}
+
+func TestGeoJSONWriterUnknownProperty (t *testing.T) {
+	if _, err := NewGeoJSONWriter(&bytes.Buffer{}, []string{"id", "designation"}); err == nil {
+		t.Error("want an error for an unknown property")
+	}
+}
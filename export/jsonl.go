@@ -0,0 +1,35 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"github.com/carterharrison/dsdata/datasheet"
+)
+
+// JSONLWriter writes one json object per line for each sheet, see https://jsonlines.org
+type JSONLWriter struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func NewJSONLWriter (w io.Writer) *JSONLWriter {
+	buf := bufio.NewWriter(w)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+
+	return &JSONLWriter{
+		buf: buf,
+		enc: enc,
+	}
+}
+
+func (writer *JSONLWriter) Write (sheet datasheet.DataSheet) error {
+	// the encoder ends each object with a new line
+	return writer.enc.Encode(sheet)
+}
+
+func (writer *JSONLWriter) Close () error {
+	return writer.buf.Flush()
+}
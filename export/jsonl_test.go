@@ -0,0 +1,52 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/carterharrison/dsdata/datasheet"
+)
+
+func TestJSONLWriter (t *testing.T) {
+	var out bytes.Buffer
+	w := NewJSONLWriter(&out)
+
+	for _, id := range []string{"HV4612", "DF4370"} {
+		sheet := datasheet.DataSheet{}
+		sheet.Init()
+		sheet.Id = id
+		sheet.BasicMetadata["DESIGNATION"] = "A & B"
+
+		if err := w.Write(sheet); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+
+	for i, want := range []string{"HV4612", "DF4370"} {
+		var sheet datasheet.DataSheet
+
+		if err := json.Unmarshal([]byte(lines[i]), &sheet); err != nil {
+			t.Fatal(err)
+		}
+
+		if sheet.Id != want {
+			t.Errorf("line %d is %s, want %s", i, sheet.Id, want)
+		}
+	}
+
+	if !strings.Contains(lines[0], `"A & B"`) {
+		t.Errorf("html was escaped: %s", lines[0])
+	}
+}
@@ -0,0 +1,313 @@
+package mirror
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// what is around the numbers of a PASV reply, ex: 227 Entering Passive Mode (192,168,1,2,195,80)
+var pasvSeparators = strings.NewReplacer("(", " ", ")", " ", ",", " ")
+
+// an archive on an ftp server, one control connection is kept open and every transfer is
+// passive and binary
+type ftpSource struct {
+	mu sync.Mutex
+	text *textproto.Conn
+	host string
+}
+
+// logs in, anonymous unless the url has a user, and goes to the folder of the url
+func dialFTP (ctx context.Context, u *url.URL) (*ftpSource, error) {
+	addr := u.Host
+
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "21")
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	source := &ftpSource{text: textproto.NewConn(conn), host: u.Hostname()}
+
+	if _, _, err := source.text.ReadResponse(220); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	user, password := "anonymous", "anonymous@"
+
+	if u.User != nil {
+		user = u.User.Username()
+
+		if p, ok := u.User.Password(); ok {
+			password = p
+		}
+	}
+
+	code, _, err := source.cmd(0, "USER %s", user)
+
+	// 230 is logged in without a password
+	if err == nil && code == 331 {
+		_, _, err = source.cmd(230, "PASS %s", password)
+	} else if err == nil && code != 230 {
+		err = fmt.Errorf("mirror: ftp login as %s: %d", user, code)
+	}
+
+	if err == nil {
+		_, _, err = source.cmd(200, "TYPE I")
+	}
+
+	if dir := strings.TrimPrefix(u.Path, "/"); err == nil && dir != "" {
+		_, _, err = source.cmd(250, "CWD /%s", dir)
+	}
+
+	if err != nil {
+		source.Close()
+		return nil, err
+	}
+
+	return source, nil
+}
+
+// the names of NLST with the size and modification time of each, the ones without a size
+// are folders
+func (source *ftpSource) List (ctx context.Context) ([]Remote, error) {
+	source.mu.Lock()
+	defer source.mu.Unlock()
+
+	data, err := source.transfer(ctx, "NLST")
+
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0)
+	scanner := bufio.NewScanner(data)
+
+	for scanner.Scan() {
+		// some servers list the names with the folder in front
+		name := strings.TrimSpace(scanner.Text())
+
+		if i := strings.LastIndexByte(name, '/'); i >= 0 {
+			name = name[i + 1:]
+		}
+
+		if name != "" && name != "." && name != ".." {
+			names = append(names, name)
+		}
+	}
+
+	if err := source.finish(data, scanner.Err()); err != nil {
+		return nil, err
+	}
+
+	remotes := make([]Remote, 0, len(names))
+
+	for _, name := range names {
+		code, msg, err := source.cmd(0, "SIZE %s", name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if code != 213 {
+			continue
+		}
+
+		size, err := strconv.ParseInt(strings.TrimSpace(msg), 10, 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("mirror: size of %s: %q", name, msg)
+		}
+
+		remote := Remote{Name: name, Size: size}
+
+		if code, msg, err := source.cmd(0, "MDTM %s", name); err != nil {
+			return nil, err
+		} else if code == 213 {
+			remote.ModTime, _ = parseMDTM(strings.TrimSpace(msg))
+		}
+
+		remotes = append(remotes, remote)
+	}
+
+	return remotes, nil
+}
+
+// RETR of the file, after a REST to the offset. a server that does not take the REST
+// sends it from the beginning
+func (source *ftpSource) Fetch (ctx context.Context, remote Remote, offset int64) (io.ReadCloser, int64, error) {
+	source.mu.Lock()
+
+	if offset > 0 {
+		code, _, err := source.cmd(0, "REST %d", offset)
+
+		if err != nil {
+			source.mu.Unlock()
+			return nil, 0, err
+		}
+
+		if code != 350 {
+			offset = 0
+		}
+	}
+
+	data, err := source.transfer(ctx, "RETR %s", remote.Name)
+
+	if err != nil {
+		source.mu.Unlock()
+		return nil, 0, err
+	}
+
+	return &ftpReader{source: source, data: data, stop: closeOnDone(ctx, data)}, offset, nil
+}
+
+func (source *ftpSource) Close () error {
+	source.text.PrintfLine("QUIT")
+	return source.text.Close()
+}
+
+// sends a command and reads the reply, an expect of 0 takes any reply that is not an error
+// of the connection
+func (source *ftpSource) cmd (expect int, format string, args ...interface{}) (int, string, error) {
+	if err := source.text.PrintfLine(format, args...); err != nil {
+		return 0, "", err
+	}
+
+	code, msg, err := source.text.ReadResponse(expect)
+
+	if _, ok := err.(*textproto.Error); ok && expect == 0 {
+		err = nil
+	}
+
+	return code, msg, err
+}
+
+// opens a passive data connection and sends the command that transfers on it
+func (source *ftpSource) transfer (ctx context.Context, format string, args ...interface{}) (net.Conn, error) {
+	addr, err := source.passive()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var dialer net.Dialer
+	data, err := dialer.DialContext(ctx, "tcp", addr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	code, msg, err := source.cmd(0, format, args...)
+
+	if err == nil && code != 125 && code != 150 {
+		err = fmt.Errorf("mirror: ftp %s: %d %s", strings.Fields(format)[0], code, msg)
+	}
+
+	if err != nil {
+		data.Close()
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// the address of a data connection by EPSV, or PASV for servers without it. the host of
+// the control connection is used for both, servers behind nat give their inside address
+func (source *ftpSource) passive () (string, error) {
+	code, msg, err := source.cmd(0, "EPSV")
+
+	if err != nil {
+		return "", err
+	}
+
+	if code == 229 {
+		start := strings.Index(msg, "(|||")
+		end := strings.LastIndex(msg, "|)")
+
+		if start < 0 || end < start + 4 {
+			return "", fmt.Errorf("mirror: ftp EPSV: %q", msg)
+		}
+
+		return net.JoinHostPort(source.host, msg[start + 4:end]), nil
+	}
+
+	_, msg, err = source.cmd(227, "PASV")
+
+	if err != nil {
+		return "", err
+	}
+
+	fields := strings.Fields(pasvSeparators.Replace(msg))
+
+	if len(fields) < 6 {
+		return "", fmt.Errorf("mirror: ftp PASV: %q", msg)
+	}
+
+	high, err1 := strconv.Atoi(fields[len(fields) - 2])
+	low, err2 := strconv.Atoi(fields[len(fields) - 1])
+
+	if err1 != nil || err2 != nil {
+		return "", fmt.Errorf("mirror: ftp PASV: %q", msg)
+	}
+
+	return net.JoinHostPort(source.host, strconv.Itoa(high * 256 + low)), nil
+}
+
+// closes the data connection and reads the reply to the transfer, the error of reading the
+// data comes first
+func (source *ftpSource) finish (data net.Conn, readErr error) error {
+	data.Close()
+	_, _, err := source.text.ReadResponse(2)
+
+	if readErr != nil {
+		return readErr
+	}
+
+	return err
+}
+
+// the data of a RETR, closing it ends the transfer and lets the next command go
+type ftpReader struct {
+	source *ftpSource
+	data net.Conn
+	stop func ()
+	once sync.Once
+	err error
+}
+
+func (reader *ftpReader) Read (p []byte) (int, error) {
+	return reader.data.Read(p)
+}
+
+func (reader *ftpReader) Close () error {
+	reader.once.Do(func () {
+		reader.stop()
+		reader.err = reader.source.finish(reader.data, nil)
+		reader.source.mu.Unlock()
+	})
+
+	return reader.err
+}
+
+// the modification time of an MDTM, ex: 20240131120000 or 20240131120000.123
+func parseMDTM (value string) (time.Time, error) {
+	if i := strings.IndexByte(value, '.'); i >= 0 {
+		value = value[:i]
+	}
+
+	return time.Parse("20060102150405", value)
+}
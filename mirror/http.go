@@ -0,0 +1,164 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// the links of a directory index
+var hrefPattern = regexp.MustCompile(`(?i)href\s*=\s*["']([^"']+)["']`)
+
+// an archive on a web server with directory indexes, like https://geodesy.noaa.gov/pub/
+type httpSource struct {
+	base *url.URL
+	client *http.Client
+}
+
+func newHTTPSource (base *url.URL) *httpSource {
+	folder := *base
+
+	if !strings.HasSuffix(folder.Path, "/") {
+		folder.Path += "/"
+	}
+
+	return &httpSource{base: &folder, client: http.DefaultClient}
+}
+
+// the files linked from the index of the folder, with what a HEAD of each says about it
+func (source *httpSource) List (ctx context.Context) ([]Remote, error) {
+	resp, err := source.do(ctx, "GET", source.base, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("mirror: %s: %s", source.base, resp.Status)
+	}
+
+	index, err := io.ReadAll(resp.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	remotes := make([]Remote, 0)
+	seen := make(map[string]bool)
+
+	for _, match := range hrefPattern.FindAllSubmatch(index, -1) {
+		name, ok := source.fileName(string(match[1]))
+
+		if !ok || seen[name] {
+			continue
+		}
+
+		seen[name] = true
+		remote, err := source.head(ctx, name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		remotes = append(remotes, remote)
+	}
+
+	return remotes, nil
+}
+
+// the name of the file a link is to when it is a file right in the folder, not the
+// parent, a sub folder, a sort link or another site
+func (source *httpSource) fileName (href string) (string, bool) {
+	link, err := source.base.Parse(href)
+
+	if err != nil || link.Host != source.base.Host || link.RawQuery != "" || !strings.HasPrefix(link.Path, source.base.Path) {
+		return "", false
+	}
+
+	name := strings.TrimPrefix(link.Path, source.base.Path)
+	return name, name != "" && !strings.Contains(name, "/")
+}
+
+func (source *httpSource) head (ctx context.Context, name string) (Remote, error) {
+	resp, err := source.do(ctx, "HEAD", source.fileURL(name), nil)
+
+	if err != nil {
+		return Remote{}, err
+	}
+
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Remote{}, fmt.Errorf("mirror: %s: %s", source.fileURL(name), resp.Status)
+	}
+
+	remote := Remote{Name: name, Size: resp.ContentLength, ETag: resp.Header.Get("ETag")}
+
+	if modTime, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		remote.ModTime = modTime.UTC()
+	}
+
+	return remote, nil
+}
+
+// the file from the offset by a range request, If-Range makes the server send all of it
+// when it changed since the list
+func (source *httpSource) Fetch (ctx context.Context, remote Remote, offset int64) (io.ReadCloser, int64, error) {
+	header := make(http.Header)
+
+	if offset > 0 {
+		header.Set("Range", "bytes=" + strconv.FormatInt(offset, 10) + "-")
+
+		switch {
+		case remote.ETag != "" && !strings.HasPrefix(remote.ETag, "W/"): header.Set("If-Range", remote.ETag)
+		case !remote.ModTime.IsZero(): header.Set("If-Range", remote.ModTime.Format(http.TimeFormat))
+		default: header.Del("Range")
+		}
+	}
+
+	resp, err := source.do(ctx, "GET", source.fileURL(remote.Name), header)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK: return resp.Body, 0, nil
+	case http.StatusPartialContent:
+		if strings.HasPrefix(resp.Header.Get("Content-Range"), "bytes " + strconv.FormatInt(offset, 10) + "-") {
+			return resp.Body, offset, nil
+		}
+	}
+
+	resp.Body.Close()
+	return nil, 0, fmt.Errorf("mirror: %s: %s", source.fileURL(remote.Name), resp.Status)
+}
+
+func (source *httpSource) Close () error {
+	return nil
+}
+
+func (source *httpSource) fileURL (name string) *url.URL {
+	return source.base.ResolveReference(&url.URL{Path: name})
+}
+
+func (source *httpSource) do (ctx context.Context, method string, u *url.URL, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	return source.client.Do(req)
+}
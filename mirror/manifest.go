@@ -0,0 +1,90 @@
+package mirror
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ManifestName is the name of the manifest in the folder of a mirror
+var ManifestName = "manifest.json"
+
+// Manifest is what was fetched from the archive and when
+type Manifest struct {
+	// the url of the archive
+	Source string `json:"source"`
+
+	// the files fetched by their name
+	Files map[string]File `json:"files"`
+
+	// the files being fetched as they were when it started, so a file that was cut off is
+	// only resumed when it did not change since
+	Partial map[string]Remote `json:"partial,omitempty"`
+}
+
+// File is a file that was fetched
+type File struct {
+	Remote
+
+	// the sha256 of the file in hex
+	SHA256 string `json:"sha256"`
+
+	Fetched time.Time `json:"fetched"`
+}
+
+// LoadManifest reads the manifest, one that is not there is empty
+func LoadManifest (path string) (*Manifest, error) {
+	manifest := &Manifest{Files: make(map[string]File), Partial: make(map[string]Remote)}
+	data, err := os.ReadFile(path)
+
+	if os.IsNotExist(err) {
+		return manifest, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, err
+	}
+
+	if manifest.Files == nil {
+		manifest.Files = make(map[string]File)
+	}
+
+	if manifest.Partial == nil {
+		manifest.Partial = make(map[string]Remote)
+	}
+
+	return manifest, nil
+}
+
+// Save writes the manifest, through a temporary file so it is never half written
+func (manifest *Manifest) Save (path string) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), ".manifest")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(append(data, '\n')); err != nil {
+		temp.Close()
+		return err
+	}
+
+	if err := temp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(temp.Name(), path)
+}
@@ -0,0 +1,339 @@
+// Package mirror keeps a local copy of the datasheet archive, by default the DataSheets
+// folder of the ngs ftp site. files are fetched over ftp or http, a download that was cut
+// off is resumed and a file that did not change since it was fetched is skipped, by its
+// etag or its size and modification time. what was fetched and when is kept in a manifest
+// next to the files.
+package mirror
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Archive is the folder the ngs publishes the datasheets in
+var Archive = "ftp://ftp.ngs.noaa.gov/pub/DS_ARCHIVE/DataSheets/"
+
+// Remote is a file of the archive as the server lists it
+type Remote struct {
+	Name string `json:"name"`
+
+	// -1 when the server does not say
+	Size int64 `json:"size"`
+
+	// zero when the server does not say
+	ModTime time.Time `json:"modTime"`
+
+	// only over http
+	ETag string `json:"etag,omitempty"`
+}
+
+// Same is whether the two are the same version of a file, by the etag when both have one
+// and by the size and modification time otherwise
+func (remote Remote) Same (other Remote) bool {
+	if remote.ETag != "" && other.ETag != "" {
+		return remote.ETag == other.ETag
+	}
+
+	return remote.Size == other.Size && remote.ModTime.Equal(other.ModTime)
+}
+
+// Source is a server with the archive on it
+type Source interface {
+	// List is the files in the folder of the archive, not the folders in it
+	List (ctx context.Context) ([]Remote, error)
+
+	// Fetch reads the file from the offset. a server that can not resume starts from the
+	// beginning, the offset the reader starts at is returned
+	Fetch (ctx context.Context, remote Remote, offset int64) (io.ReadCloser, int64, error)
+
+	Close () error
+}
+
+// Open is the source of an ftp:// or http(s):// url of a folder
+func Open (ctx context.Context, rawurl string) (Source, error) {
+	u, err := url.Parse(rawurl)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "ftp": return dialFTP(ctx, u)
+	case "http", "https": return newHTTPSource(u), nil
+	}
+
+	return nil, fmt.Errorf("mirror: can not fetch %s, only ftp and http", rawurl)
+}
+
+// Mirror copies the files of an archive into a folder
+type Mirror struct {
+	// the folder of the archive, Archive when empty
+	URL string
+
+	// the folder the files and the manifest are written to
+	Dir string
+
+	// a glob the names of the files are matched with, ex: *.zip, every file when empty
+	Match string
+
+	// whether to hash the files already fetched and fetch them again when they changed
+	Verify bool
+
+	// where each file fetched is logged, log.Printf when nil
+	Logf func (format string, args ...interface{})
+}
+
+// Report is what a sync did with each file of the archive
+type Report struct {
+	// fetched from the beginning
+	Fetched []string
+
+	// fetched from where an earlier sync was cut off
+	Resumed []string
+
+	// the same as when they were last fetched
+	Skipped []string
+
+	// bytes read from the server
+	Bytes int64
+}
+
+// Sync fetches every file of the archive that is not in the folder as it is on the server.
+// it stops on the first file that can not be fetched, what was fetched of it is kept and
+// the next sync goes on from there
+func (mirror *Mirror) Sync (ctx context.Context) (Report, error) {
+	report := Report{}
+	source := mirror.URL
+
+	if source == "" {
+		source = Archive
+	}
+
+	if err := os.MkdirAll(mirror.Dir, 0755); err != nil {
+		return report, err
+	}
+
+	manifestPath := filepath.Join(mirror.Dir, ManifestName)
+	manifest, err := LoadManifest(manifestPath)
+
+	if err != nil {
+		return report, err
+	}
+
+	manifest.Source = source
+	server, err := Open(ctx, source)
+
+	if err != nil {
+		return report, err
+	}
+
+	defer server.Close()
+	remotes, err := server.List(ctx)
+
+	if err != nil {
+		return report, err
+	}
+
+	sort.Slice(remotes, func (i, j int) bool {
+		return remotes[i].Name < remotes[j].Name
+	})
+
+	for _, remote := range remotes {
+		if mirror.Match != "" {
+			if ok, err := path.Match(mirror.Match, remote.Name); err != nil || !ok {
+				continue
+			}
+		}
+
+		current, err := mirror.current(manifest, remote)
+
+		if err != nil {
+			return report, err
+		}
+
+		if current {
+			report.Skipped = append(report.Skipped, remote.Name)
+			continue
+		}
+
+		if err := mirror.fetch(ctx, server, manifest, manifestPath, remote, &report); err != nil {
+			return report, fmt.Errorf("%s: %w", remote.Name, err)
+		}
+	}
+
+	return report, manifest.Save(manifestPath)
+}
+
+// whether the file in the folder is the one on the server
+func (mirror *Mirror) current (manifest *Manifest, remote Remote) (bool, error) {
+	file, ok := manifest.Files[remote.Name]
+
+	if !ok || !file.Same(remote) {
+		return false, nil
+	}
+
+	info, err := os.Stat(filepath.Join(mirror.Dir, remote.Name))
+
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	if info.Size() != file.Size && file.Size >= 0 {
+		return false, nil
+	}
+
+	if !mirror.Verify {
+		return true, nil
+	}
+
+	sum, err := hashFile(filepath.Join(mirror.Dir, remote.Name))
+	return sum == file.SHA256, err
+}
+
+// fetches the file into name.part and moves it over the old one once it is all there
+func (mirror *Mirror) fetch (ctx context.Context, server Source, manifest *Manifest, manifestPath string, remote Remote, report *Report) error {
+	dest := filepath.Join(mirror.Dir, remote.Name)
+	part := dest + ".part"
+	offset := int64(0)
+
+	// only what was fetched of this version of the file is resumed
+	if partial, ok := manifest.Partial[remote.Name]; ok && partial.Same(remote) {
+		if info, err := os.Stat(part); err == nil {
+			offset = info.Size()
+		}
+	}
+
+	manifest.Partial[remote.Name] = remote
+
+	if err := manifest.Save(manifestPath); err != nil {
+		return err
+	}
+
+	body, start, err := server.Fetch(ctx, remote, offset)
+
+	if err != nil {
+		return err
+	}
+
+	defer body.Close()
+	file, err := os.OpenFile(part, os.O_WRONLY | os.O_CREATE, 0644)
+
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	if err := file.Truncate(start); err != nil {
+		return err
+	}
+
+	if _, err := file.Seek(start, io.SeekStart); err != nil {
+		return err
+	}
+
+	written, err := io.Copy(file, body)
+	report.Bytes += written
+
+	if err != nil {
+		return err
+	}
+
+	// the end of the transfer is only known once the server says so
+	if err := body.Close(); err != nil {
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if remote.Size >= 0 && start + written != remote.Size {
+		return fmt.Errorf("got %d bytes, want %d", start + written, remote.Size)
+	}
+
+	sum, err := hashFile(part)
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.Rename(part, dest); err != nil {
+		return err
+	}
+
+	if !remote.ModTime.IsZero() {
+		os.Chtimes(dest, remote.ModTime, remote.ModTime)
+	}
+
+	manifest.Files[remote.Name] = File{Remote: remote, SHA256: sum, Fetched: time.Now().UTC()}
+	delete(manifest.Partial, remote.Name)
+
+	if start > 0 {
+		report.Resumed = append(report.Resumed, remote.Name)
+		mirror.logf("%s: resumed at %d, %d bytes", remote.Name, start, start + written)
+	} else {
+		report.Fetched = append(report.Fetched, remote.Name)
+		mirror.logf("%s: %d bytes", remote.Name, written)
+	}
+
+	return manifest.Save(manifestPath)
+}
+
+func (mirror *Mirror) logf (format string, args ...interface{}) {
+	if mirror.Logf != nil {
+		mirror.Logf(format, args...)
+		return
+	}
+
+	log.Printf(format, args...)
+}
+
+// the sha256 of the file in hex
+func hashFile (name string) (string, error) {
+	file, err := os.Open(name)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer file.Close()
+	hash := sha256.New()
+
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// closes the closer when the context is done before stop is called, so a read from it
+// does not block past the context
+func closeOnDone (ctx context.Context, closer io.Closer) (stop func ()) {
+	done := make(chan struct{})
+
+	go func () {
+		select {
+		case <-ctx.Done(): closer.Close()
+		case <-done:
+		}
+	}()
+
+	return func () {
+		close(done)
+	}
+}
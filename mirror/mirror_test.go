@@ -0,0 +1,436 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// the files of the test archive
+func archiveFiles () map[string][]byte {
+	return map[string][]byte{
+		"CA.txt": bytes.Repeat([]byte("1 DATASHEETS - PROGRAM = datasheet95\n"), 400),
+		"NV.zip": bytes.Repeat([]byte{0, 1, 2, 3, 4, 5, 6, 7}, 2000),
+	}
+}
+
+// a web server over a folder like the ngs one, the first full GET of cut is cut off halfway
+func startHTTP (root string, cut string) *httptest.Server {
+	files := http.FileServer(http.Dir(root))
+	var once sync.Once
+
+	return httptest.NewServer(http.HandlerFunc(func (w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/")
+
+		if data, err := os.ReadFile(filepath.Join(root, name)); err == nil {
+			w.Header().Set("ETag", `"` + strconv.Itoa(len(data)) + "-" + strconv.Itoa(int(data[len(data) - 1])) + `"`)
+
+			cutting := false
+
+			if name == cut && r.Method == "GET" && r.Header.Get("Range") == "" {
+				once.Do(func () {
+					cutting = true
+				})
+			}
+
+			if cutting {
+				w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+				w.Write(data[:len(data) / 2])
+				w.(http.Flusher).Flush()
+				panic(http.ErrAbortHandler)
+			}
+		}
+
+		files.ServeHTTP(w, r)
+	}))
+}
+
+// a stand-in ftp server with the files and a sub folder, the first RETR of cut is cut off
+// after half of it
+type ftpServer struct {
+	listener net.Listener
+	files map[string][]byte
+	modTime time.Time
+	cut string
+
+	mu sync.Mutex
+	cutDone bool
+	rests []int64
+}
+
+func startFTP (t *testing.T, files map[string][]byte, cut string) *ftpServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := &ftpServer{listener: listener, files: files, modTime: time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC), cut: cut}
+	t.Cleanup(func () {
+		listener.Close()
+	})
+
+	go func () {
+		for {
+			conn, err := listener.Accept()
+
+			if err != nil {
+				return
+			}
+
+			go server.serve(conn)
+		}
+	}()
+
+	return server
+}
+
+func (server *ftpServer) URL () string {
+	return "ftp://" + server.listener.Addr().String() + "/pub/DS_ARCHIVE/DataSheets"
+}
+
+func (server *ftpServer) serve (conn net.Conn) {
+	text := textproto.NewConn(conn)
+	defer text.Close()
+
+	var data net.Listener
+	rest := int64(0)
+	text.PrintfLine("220 ready")
+
+	for {
+		line, err := text.ReadLine()
+
+		if err != nil {
+			return
+		}
+
+		cmd, arg := line, ""
+
+		if i := strings.IndexByte(line, ' '); i >= 0 {
+			cmd, arg = line[:i], line[i + 1:]
+		}
+
+		switch cmd {
+		case "USER": text.PrintfLine("331 password")
+		case "PASS": text.PrintfLine("230 logged in")
+		case "TYPE": text.PrintfLine("200 binary")
+		case "CWD": text.PrintfLine("250 ok")
+		case "EPSV":
+			data, _ = net.Listen("tcp", "127.0.0.1:0")
+			text.PrintfLine("229 Entering Extended Passive Mode (|||%d|)", data.Addr().(*net.TCPAddr).Port)
+		case "NLST":
+			names := []string{"old"}
+
+			for name := range server.files {
+				names = append(names, name)
+			}
+
+			server.send(text, data, []byte(strings.Join(names, "\r\n") + "\r\n"), -1)
+		case "SIZE":
+			if file, ok := server.files[arg]; ok {
+				text.PrintfLine("213 %d", len(file))
+			} else {
+				text.PrintfLine("550 not a file")
+			}
+		case "MDTM": text.PrintfLine("213 %s", server.modTime.Format("20060102150405"))
+		case "REST":
+			rest, _ = strconv.ParseInt(arg, 10, 64)
+			server.mu.Lock()
+			server.rests = append(server.rests, rest)
+			server.mu.Unlock()
+			text.PrintfLine("350 restarting at %d", rest)
+		case "RETR":
+			file := server.files[arg][rest:]
+			limit := -1
+
+			server.mu.Lock()
+
+			if arg == server.cut && !server.cutDone {
+				server.cutDone = true
+				limit = len(file) / 2
+			}
+
+			server.mu.Unlock()
+			server.send(text, data, file, limit)
+			rest = 0
+		case "QUIT":
+			text.PrintfLine("221 bye")
+			return
+		default: text.PrintfLine("502 %s", cmd)
+		}
+	}
+}
+
+// sends the data on the next connection to the listener, only limit bytes of it when it is
+// not -1 and then the transfer is aborted
+func (server *ftpServer) send (text *textproto.Conn, data net.Listener, body []byte, limit int) {
+	text.PrintfLine("150 sending")
+	conn, err := data.Accept()
+	data.Close()
+
+	if err != nil {
+		text.PrintfLine("425 no connection")
+		return
+	}
+
+	if limit >= 0 {
+		conn.Write(body[:limit])
+		conn.Close()
+		text.PrintfLine("426 aborted")
+		return
+	}
+
+	conn.Write(body)
+	conn.Close()
+	text.PrintfLine("226 done")
+}
+
+func (server *ftpServer) Rests () []int64 {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	return append([]int64(nil), server.rests...)
+}
+
+func writeArchive (t *testing.T, dir string, files map[string][]byte) {
+	if err := os.MkdirAll(filepath.Join(dir, "old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func quiet (format string, args ...interface{}) {}
+
+// checks the files of the mirror are the ones of the archive, with their hash in the manifest
+func checkMirror (t *testing.T, dir string, files map[string][]byte) {
+	t.Helper()
+	manifest, err := LoadManifest(filepath.Join(dir, ManifestName))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range files {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("%s is not the file of the archive: %v", name, err)
+		}
+
+		if sum, _ := hashFile(filepath.Join(dir, name)); manifest.Files[name].SHA256 != sum || manifest.Files[name].Fetched.IsZero() {
+			t.Errorf("%s is in the manifest as %+v", name, manifest.Files[name])
+		}
+	}
+
+	if len(manifest.Partial) != 0 {
+		t.Errorf("the manifest still has %v being fetched", manifest.Partial)
+	}
+}
+
+func TestSyncHTTP (t *testing.T) {
+	root := t.TempDir()
+	files := archiveFiles()
+	writeArchive(t, root, files)
+
+	server := startHTTP(root, "")
+	defer server.Close()
+
+	dir := t.TempDir()
+	mirror := &Mirror{URL: server.URL + "/", Dir: dir, Logf: quiet}
+	report, err := mirror.Sync(context.Background())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"CA.txt", "NV.zip"}; !reflect.DeepEqual(report.Fetched, want) {
+		t.Errorf("fetched %v, want %v", report.Fetched, want)
+	}
+
+	checkMirror(t, dir, files)
+
+	// nothing changed
+	report, err = mirror.Sync(context.Background())
+
+	if err != nil || len(report.Fetched) != 0 || len(report.Skipped) != 2 || report.Bytes != 0 {
+		t.Errorf("the second sync got %+v %v", report, err)
+	}
+
+	// a new release of one file
+	files["CA.txt"] = append(files["CA.txt"], "1 DATASHEETS - PROGRAM = datasheet95\n"...)
+	writeArchive(t, root, files)
+	later := time.Now().Add(time.Hour)
+	os.Chtimes(filepath.Join(root, "CA.txt"), later, later)
+
+	report, err = mirror.Sync(context.Background())
+
+	if err != nil || !reflect.DeepEqual(report.Fetched, []string{"CA.txt"}) || !reflect.DeepEqual(report.Skipped, []string{"NV.zip"}) {
+		t.Errorf("the sync after a change got %+v %v", report, err)
+	}
+
+	checkMirror(t, dir, files)
+
+	// a file changed in the mirror is only seen when verifying
+	os.WriteFile(filepath.Join(dir, "NV.zip"), bytes.Repeat([]byte{9}, len(files["NV.zip"])), 0644)
+	mirror.Verify = true
+	report, err = mirror.Sync(context.Background())
+
+	if err != nil || !reflect.DeepEqual(report.Fetched, []string{"NV.zip"}) {
+		t.Errorf("the verifying sync got %+v %v", report, err)
+	}
+
+	checkMirror(t, dir, files)
+}
+
+func TestSyncFTP (t *testing.T) {
+	files := archiveFiles()
+	server := startFTP(t, files, "")
+	dir := t.TempDir()
+
+	mirror := &Mirror{URL: server.URL(), Dir: dir, Match: "*.txt", Logf: quiet}
+	report, err := mirror.Sync(context.Background())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(report.Fetched, []string{"CA.txt"}) || report.Bytes != int64(len(files["CA.txt"])) {
+		t.Errorf("got %+v", report)
+	}
+
+	delete(files, "NV.zip")
+	checkMirror(t, dir, files)
+
+	if info, err := os.Stat(filepath.Join(dir, "CA.txt")); err != nil || !info.ModTime().Equal(server.modTime) {
+		t.Errorf("CA.txt was not given the time of the server: %v", err)
+	}
+
+	report, err = mirror.Sync(context.Background())
+
+	if err != nil || !reflect.DeepEqual(report.Skipped, []string{"CA.txt"}) {
+		t.Errorf("the second sync got %+v %v", report, err)
+	}
+}
+
+func TestSyncResume (t *testing.T) {
+	files := archiveFiles()
+	root := t.TempDir()
+	writeArchive(t, root, files)
+
+	web := startHTTP(root, "NV.zip")
+	defer web.Close()
+
+	ftp := startFTP(t, files, "NV.zip")
+
+	for _, url := range []string{web.URL, ftp.URL()} {
+		dir := t.TempDir()
+		mirror := &Mirror{URL: url, Dir: dir, Logf: quiet}
+
+		if _, err := mirror.Sync(context.Background()); err == nil {
+			t.Errorf("%s: the cut off sync did not fail", url)
+		}
+
+		half := int64(len(files["NV.zip"]) / 2)
+
+		if info, err := os.Stat(filepath.Join(dir, "NV.zip.part")); err != nil || info.Size() != half {
+			t.Fatalf("%s: the part fetched is not kept: %v", url, err)
+		}
+
+		report, err := mirror.Sync(context.Background())
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(report.Resumed, []string{"NV.zip"}) || report.Bytes != int64(len(files["NV.zip"])) - half {
+			t.Errorf("%s: got %+v", url, report)
+		}
+
+		checkMirror(t, dir, files)
+
+		if _, err := os.Stat(filepath.Join(dir, "NV.zip.part")); !os.IsNotExist(err) {
+			t.Errorf("%s: the part is still there", url)
+		}
+	}
+
+	if rests := ftp.Rests(); !reflect.DeepEqual(rests, []int64{int64(len(files["NV.zip"]) / 2)}) {
+		t.Errorf("the ftp server got REST %v", rests)
+	}
+}
+
+func TestSyncUnchangedPartial (t *testing.T) {
+	files := archiveFiles()
+	dir := t.TempDir()
+
+	// a part of another version of the file is not resumed
+	os.WriteFile(filepath.Join(dir, "CA.txt.part"), []byte("old"), 0644)
+	manifest := &Manifest{Files: map[string]File{}, Partial: map[string]Remote{"CA.txt": {Name: "CA.txt", Size: 3}}}
+
+	if err := manifest.Save(filepath.Join(dir, ManifestName)); err != nil {
+		t.Fatal(err)
+	}
+
+	server := startFTP(t, files, "")
+	report, err := (&Mirror{URL: server.URL(), Dir: dir, Logf: quiet}).Sync(context.Background())
+
+	if err != nil || len(report.Resumed) != 0 || len(server.Rests()) != 0 {
+		t.Errorf("got %+v %v, REST %v", report, err, server.Rests())
+	}
+
+	checkMirror(t, dir, files)
+}
+
+func TestOpen (t *testing.T) {
+	for _, url := range []string{"sftp://example.com/", "file:///tmp"} {
+		if _, err := Open(context.Background(), url); err == nil {
+			t.Errorf("%s opened", url)
+		}
+	}
+
+	source, err := Open(context.Background(), "https://geodesy.noaa.gov/pub/DS_ARCHIVE/DataSheets")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		href string
+		name string
+	}{
+		{"CA.zip", "CA.zip"},
+		{"/pub/DS_ARCHIVE/DataSheets/NV.zip", "NV.zip"},
+		{"https://geodesy.noaa.gov/pub/DS_ARCHIVE/DataSheets/AK%20old.zip", "AK old.zip"},
+		{"../", ""},
+		{"?C=M;O=A", ""},
+		{"old/", ""},
+		{"https://example.com/pub/DS_ARCHIVE/DataSheets/CA.zip", ""},
+	}
+
+	for _, tt := range tests {
+		name, ok := source.(*httpSource).fileName(tt.href)
+
+		if !ok {
+			name = ""
+		}
+
+		if name != tt.name {
+			t.Errorf("%s: got %q, want %q", tt.href, name, tt.name)
+		}
+	}
+}
@@ -1,10 +1,8 @@
 #!/bin/bash
 
-NUM=0
+# the archive files only, sync keeps its manifest.json and name.part downloads in the folder too
+shopt -s nullglob
 
-for FILENAME in ./temp/ftp.ngs.noaa.gov/pub/DS_ARCHIVE/DataSheets/*.txt; do
-  ./dsdata "$FILENAME"
-#	GO=$(./dsdata "$FILENAME" | wc -l)
-#	NUM="$((NUM+GO))"
-#	echo $NUM
-done
+# every state file of the archive as one json object per sheet
+./dsdata sync --dir DataSheets
+./dsdata export --format jsonl -o datasheets.jsonl DataSheets/*.txt DataSheets/*.zip
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/carterharrison/dsdata/datasheet"
+	"github.com/carterharrison/dsdata/spatial"
+)
+
+// Archive is every sheet of the files the server was started with, it is not changed once
+// loaded, a reload makes a new one
+type Archive struct {
+	sheets map[datasheet.PID]*datasheet.DataSheet
+
+	// the pids of the sheets by their upper case designation, sorted
+	designations map[string][]datasheet.PID
+
+	// every pid, sorted
+	pids []datasheet.PID
+
+	spatial *spatial.Index
+
+	// the size and modification time of each file when it was loaded
+	files map[string]fileStamp
+}
+
+type fileStamp struct {
+	size int64
+	modTime time.Time
+}
+
+// LoadArchive parses the files, sheets with parse errors are kept. a later sheet with the
+// pid of an earlier one replaces it
+func LoadArchive (ctx context.Context, paths []string, pipeline datasheet.Pipeline) (*Archive, error) {
+	archive := &Archive{
+		sheets: make(map[datasheet.PID]*datasheet.DataSheet),
+		designations: make(map[string][]datasheet.PID),
+		spatial: spatial.NewIndex(),
+		files: make(map[string]fileStamp),
+	}
+
+	// stamped before reading so a change made while loading is seen by the next check
+	for _, path := range paths {
+		stamp, err := stat(path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		archive.files[path] = stamp
+	}
+
+	inputs := make([]datasheet.Input, len(paths))
+
+	for i, path := range paths {
+		inputs[i] = datasheet.FileInput(path)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pipeline.Ordered = true
+
+	for result := range pipeline.Run(ctx, inputs) {
+		if _, ok := result.Err.(datasheet.ParseErrors); !ok && result.Err != nil {
+			return nil, result.Err
+		}
+
+		if result.Sheet.Id == "" {
+			continue
+		}
+
+		sheet := result.Sheet
+		archive.sheets[datasheet.PID(sheet.Id)] = &sheet
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for pid, sheet := range archive.sheets {
+		archive.pids = append(archive.pids, pid)
+		archive.spatial.Add(*sheet)
+
+		designation := normalizeDesignation(sheet.BasicMetadata["DESIGNATION"])
+		archive.designations[designation] = append(archive.designations[designation], pid)
+	}
+
+	sortPIDs(archive.pids)
+
+	for _, pids := range archive.designations {
+		sortPIDs(pids)
+	}
+
+	// built now so the queries of many requests at once only read it
+	archive.spatial.Build()
+
+	return archive, nil
+}
+
+// Len is the number of sheets
+func (archive *Archive) Len () int {
+	return len(archive.sheets)
+}
+
+// Sheet is the sheet of the pid, the case of the pid does not matter
+func (archive *Archive) Sheet (pid string) (*datasheet.DataSheet, bool) {
+	sheet, ok := archive.sheets[datasheet.PID(strings.ToUpper(pid))]
+	return sheet, ok
+}
+
+// Designation is the sheets named designation, the case and spaces around it do not matter
+func (archive *Archive) Designation (designation string) []datasheet.PID {
+	return archive.designations[normalizeDesignation(designation)]
+}
+
+// Changed is true when a file was changed or removed since the archive was loaded
+func (archive *Archive) Changed () bool {
+	for path, stamp := range archive.files {
+		now, err := stat(path)
+
+		if err != nil || now != stamp {
+			return true
+		}
+	}
+
+	return false
+}
+
+func stat (path string) (fileStamp, error) {
+	info, err := os.Stat(path)
+
+	if err != nil {
+		return fileStamp{}, err
+	}
+
+	return fileStamp{size: info.Size(), modTime: info.ModTime()}, nil
+}
+
+func normalizeDesignation (s string) string {
+	return strings.Join(strings.Fields(strings.ToUpper(s)), " ")
+}
+
+func sortPIDs (pids []datasheet.PID) {
+	sort.Slice(pids, func (i, j int) bool {
+		return pids[i] < pids[j]
+	})
+}
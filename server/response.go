@@ -0,0 +1,104 @@
+package server
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// bodies smaller than this are not worth gzipping
+var gzipMinSize = 1024
+
+// writes the value as json with a weak etag of the body, the etag is weak as the body
+// is the same gzipped or not. a request with the etag in If-None-Match gets a 304
+func writeJSON (w http.ResponseWriter, r *http.Request, value interface{}) {
+	body, err := json.Marshal(value)
+
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+
+	header := w.Header()
+	header.Set("ETag", etag)
+	header.Set("Vary", "Accept-Encoding")
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	writeBody(w, r, http.StatusOK, body)
+}
+
+func writeError (w http.ResponseWriter, r *http.Request, status int, message string) {
+	body, _ := json.Marshal(map[string]string{"error": message})
+	writeBody(w, r, status, body)
+}
+
+// writes the json body, gzipped when the client takes it and it is big enough
+func writeBody (w http.ResponseWriter, r *http.Request, status int, body []byte) {
+	header := w.Header()
+	header.Set("Content-Type", "application/json")
+
+	if len(body) < gzipMinSize || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+		header.Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(status)
+		w.Write(body)
+		return
+	}
+
+	header.Set("Content-Encoding", "gzip")
+	w.WriteHeader(status)
+
+	gz := gzip.NewWriter(w)
+	gz.Write(body)
+	gz.Close()
+}
+
+// true when the If-None-Match list has the etag or is *, weak and strong etags are compared alike
+func etagMatches (ifNoneMatch string, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// gzip is in the Accept-Encoding and not turned off with q=0
+func acceptsGzip (acceptEncoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(part, ";")
+
+		if !strings.EqualFold(strings.TrimSpace(fields[0]), "gzip") && strings.TrimSpace(fields[0]) != "*" {
+			continue
+		}
+
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+
+			if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); strings.HasPrefix(param, "q=") && err == nil && q == 0 {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,340 @@
+// Package server serves the sheets of datasheet archives over http as the json of
+// datasheet.DataSheet.
+//
+//	GET /stations/{pid}                          one sheet
+//	GET /stations?designation=MOUNT%20HOPE       sheets by designation
+//	GET /stations?bbox=-117.3,32.5,-116.9,32.9   sheets in min lon, min lat, max lon, max lat
+//	GET /stations/near?lat=32.7&lon=-117.1&radius=2000
+//
+// the lists take limit and offset. every response has an etag and is gzipped when the
+// client takes it, and the archive is loaded again when its files change
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carterharrison/dsdata/datasheet"
+	"github.com/carterharrison/dsdata/spatial"
+)
+
+// the page size of the lists when no limit is asked for, and the largest one given
+var (
+	defaultLimit = 100
+	maxLimit = 1000
+)
+
+// Server is an http.Handler over an archive
+type Server struct {
+	// the files of the archive
+	Paths []string
+
+	// parses the files on load and reload
+	Pipeline datasheet.Pipeline
+
+	// where reload errors go, log.Printf when nil
+	Logf func (format string, args ...interface{})
+
+	mu sync.RWMutex
+	archive *Archive
+
+	routes sync.Once
+	mux *http.ServeMux
+}
+
+// New loads the archive of the files
+func New (ctx context.Context, paths []string) (*Server, error) {
+	server := &Server{Paths: paths}
+
+	if err := server.Reload(ctx); err != nil {
+		return nil, err
+	}
+
+	return server, nil
+}
+
+// Archive is the archive being served
+func (server *Server) Archive () *Archive {
+	server.mu.RLock()
+	defer server.mu.RUnlock()
+
+	return server.archive
+}
+
+// Reload loads the files again and serves them once they are all parsed, the requests
+// in the meantime are served from the old archive. on an error the old one is kept
+func (server *Server) Reload (ctx context.Context) error {
+	archive, err := LoadArchive(ctx, server.Paths, server.Pipeline)
+
+	if err != nil {
+		return err
+	}
+
+	server.mu.Lock()
+	server.archive = archive
+	server.mu.Unlock()
+
+	return nil
+}
+
+// Watch checks the files every interval and reloads when one changed, until the context is done
+func (server *Server) Watch (ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done(): return
+		case <-ticker.C:
+		}
+
+		if !server.Archive().Changed() {
+			continue
+		}
+
+		if err := server.Reload(ctx); err != nil {
+			server.logf("reload: %v", err)
+			continue
+		}
+
+		server.logf("reloaded %d sheets", server.Archive().Len())
+	}
+}
+
+func (server *Server) logf (format string, args ...interface{}) {
+	if server.Logf != nil {
+		server.Logf(format, args...)
+		return
+	}
+
+	log.Printf(format, args...)
+}
+
+func (server *Server) ServeHTTP (w http.ResponseWriter, r *http.Request) {
+	server.routes.Do(func () {
+		server.mux = http.NewServeMux()
+		server.mux.HandleFunc("GET /stations", server.stations)
+		server.mux.HandleFunc("GET /stations/near", server.near)
+		server.mux.HandleFunc("GET /stations/{pid}", server.station)
+	})
+
+	server.mux.ServeHTTP(w, r)
+}
+
+// a sheet in a list, with how far it is for /stations/near
+type station struct {
+	*datasheet.DataSheet
+	Distance *float64 `json:"distance,omitempty"`
+}
+
+// a page of a list
+type page struct {
+	// every sheet that matched
+	Total int `json:"total"`
+	Offset int `json:"offset"`
+	Limit int `json:"limit"`
+	Stations []station `json:"stations"`
+}
+
+func (server *Server) station (w http.ResponseWriter, r *http.Request) {
+	sheet, ok := server.Archive().Sheet(r.PathValue("pid"))
+
+	if !ok {
+		writeError(w, r, http.StatusNotFound, "no station " + r.PathValue("pid"))
+		return
+	}
+
+	writeJSON(w, r, sheet)
+}
+
+func (server *Server) stations (w http.ResponseWriter, r *http.Request) {
+	archive := server.Archive()
+	query := r.URL.Query()
+	pids := archive.pids
+
+	if designation := query.Get("designation"); designation != "" {
+		pids = archive.Designation(designation)
+	}
+
+	if bbox := query.Get("bbox"); bbox != "" {
+		nums, err := parseFloats(bbox, 4)
+
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "bbox: " + err.Error())
+			return
+		}
+
+		filter := parseFilter(query)
+		in := make(map[datasheet.PID]bool)
+
+		for _, mark := range archive.spatial.Box(nums[1], nums[0], nums[3], nums[2], filter) {
+			in[mark.PID] = true
+		}
+
+		matched := make([]datasheet.PID, 0, len(in))
+
+		for _, pid := range pids {
+			if in[pid] {
+				matched = append(matched, pid)
+			}
+		}
+
+		pids = matched
+	}
+
+	offset, limit, err := parsePage(query)
+
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result := page{Total: len(pids), Offset: offset, Limit: limit, Stations: make([]station, 0)}
+
+	for i := offset; i < len(pids) && i < offset + limit; i++ {
+		result.Stations = append(result.Stations, station{DataSheet: archive.sheets[pids[i]]})
+	}
+
+	writeJSON(w, r, result)
+}
+
+func (server *Server) near (w http.ResponseWriter, r *http.Request) {
+	archive := server.Archive()
+	query := r.URL.Query()
+
+	lat, err := strconv.ParseFloat(query.Get("lat"), 64)
+
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "lat must be decimal degrees")
+		return
+	}
+
+	lon, err := strconv.ParseFloat(query.Get("lon"), 64)
+
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "lon must be decimal degrees")
+		return
+	}
+
+	radius := 1000.0
+
+	if s := query.Get("radius"); s != "" {
+		if radius, err = strconv.ParseFloat(s, 64); err != nil || radius <= 0 {
+			writeError(w, r, http.StatusBadRequest, "radius must be meters")
+			return
+		}
+	}
+
+	filter := parseFilter(query)
+	offset, limit, err := parsePage(query)
+
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	results := archive.spatial.Radius(lat, lon, radius, filter)
+	out := page{Total: len(results), Offset: offset, Limit: limit, Stations: make([]station, 0)}
+
+	for i := offset; i < len(results) && i < offset + limit; i++ {
+		distance := results[i].Distance
+		out.Stations = append(out.Stations, station{DataSheet: archive.sheets[results[i].PID], Distance: &distance})
+	}
+
+	writeJSON(w, r, out)
+}
+
+// the offset and limit of a list, the limit is capped at maxLimit
+func parsePage (query map[string][]string) (int, int, error) {
+	offset, limit := 0, defaultLimit
+
+	if s := first(query, "offset"); s != "" {
+		n, err := strconv.Atoi(s)
+
+		if err != nil || n < 0 {
+			return 0, 0, errors.New("offset must be a whole number")
+		}
+
+		offset = n
+	}
+
+	if s := first(query, "limit"); s != "" {
+		n, err := strconv.Atoi(s)
+
+		if err != nil || n < 1 {
+			return 0, 0, errors.New("limit must be at least 1")
+		}
+
+		limit = n
+	}
+
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	return offset, limit, nil
+}
+
+// the marker, stability and condition lists of a query
+func parseFilter (query map[string][]string) spatial.Filter {
+	filter := spatial.Filter{}
+
+	for _, code := range splitList(first(query, "marker")) {
+		filter.Markers = append(filter.Markers, datasheet.Marker(code))
+	}
+
+	for _, code := range splitList(first(query, "stability")) {
+		filter.Stabilities = append(filter.Stabilities, datasheet.Stability(code))
+	}
+
+	filter.Conditions = splitList(first(query, "condition"))
+
+	return filter
+}
+
+// "1,2,3,4" => 1 2 3 4, an error unless there are n
+func parseFloats (s string, n int) ([]float64, error) {
+	parts := strings.Split(s, ",")
+
+	if len(parts) != n {
+		return nil, fmt.Errorf("want %d comma separated numbers", n)
+	}
+
+	nums := make([]float64, n)
+
+	for i, part := range parts {
+		num, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", part)
+		}
+
+		nums[i] = num
+	}
+
+	return nums, nil
+}
+
+func first (query map[string][]string, key string) string {
+	if values := query[key]; len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+// "a,b" => [a b], "" => nil
+func splitList (s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(s, ",")
+}
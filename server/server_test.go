@@ -0,0 +1,249 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// a server over copies of the datasheet test corpus, so the test can change them
+func testServer (t *testing.T) (*Server, []string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	sources, _ := filepath.Glob("../datasheet/testdata/*.txt")
+	paths := make([]string, len(sources))
+
+	for i, source := range sources {
+		data, err := os.ReadFile(source)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		paths[i] = filepath.Join(dir, filepath.Base(source))
+
+		if err := os.WriteFile(paths[i], data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server, err := New(context.Background(), paths)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return server, paths
+}
+
+func get (t *testing.T, server http.Handler, url string, header map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest("GET", url, nil)
+
+	for key, value := range header {
+		r.Header.Set(key, value)
+	}
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, r)
+
+	return w
+}
+
+// the pids of a list response
+func pagePIDs (t *testing.T, w *httptest.ResponseRecorder) (page, []string) {
+	t.Helper()
+	var p page
+
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatalf("%v: %s", err, w.Body.String())
+	}
+
+	pids := make([]string, len(p.Stations))
+
+	for i, s := range p.Stations {
+		pids[i] = s.Id
+	}
+
+	return p, pids
+}
+
+func TestStation (t *testing.T) {
+	server, _ := testServer(t)
+
+	w := get(t, server, "/stations/hv4612", nil)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d: %s", w.Code, w.Body.String())
+	}
+
+	var sheet struct {
+		Id string `json:"id"`
+		Metadata map[string]string `json:"metadata"`
+	}
+
+	if err := json.Unmarshal(w.Body.Bytes(), &sheet); err != nil || sheet.Id != "HV4612" || sheet.Metadata["DESIGNATION"] != "MOUNT HOPE" {
+		t.Errorf("got %+v %v", sheet, err)
+	}
+
+	if w := get(t, server, "/stations/ZZ9999", nil); w.Code != http.StatusNotFound {
+		t.Errorf("got %d for a missing station", w.Code)
+	}
+}
+
+func TestStationsList (t *testing.T) {
+	server, _ := testServer(t)
+
+	tests := []struct {
+		url string
+		total int
+		pids []string
+	}{
+		{"/stations", 4, []string{"DF4370", "HV4612", "KV0001", "KV0002"}},
+		{"/stations?limit=2&offset=1", 4, []string{"HV4612", "KV0001"}},
+		{"/stations?offset=10", 4, []string{}},
+		{"/stations?designation=mount%20hope", 1, []string{"HV4612"}},
+		{"/stations?bbox=-96,39,-95,40", 2, []string{"KV0001", "KV0002"}},
+		{"/stations?bbox=-96,39,-95,40&condition=GOOD", 1, []string{"KV0001"}},
+		{"/stations?bbox=-96,39,-95,40&designation=N%2035", 1, []string{"KV0001"}},
+	}
+
+	for _, tt := range tests {
+		w := get(t, server, tt.url, nil)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: got %d: %s", tt.url, w.Code, w.Body.String())
+			continue
+		}
+
+		p, pids := pagePIDs(t, w)
+
+		if p.Total != tt.total || len(pids) != len(tt.pids) {
+			t.Errorf("%s: got %d %v, want %d %v", tt.url, p.Total, pids, tt.total, tt.pids)
+			continue
+		}
+
+		for i := range pids {
+			if pids[i] != tt.pids[i] {
+				t.Errorf("%s: got %v, want %v", tt.url, pids, tt.pids)
+				break
+			}
+		}
+	}
+
+	for _, url := range []string{"/stations?bbox=1,2,3", "/stations?limit=0", "/stations?offset=-1"} {
+		if w := get(t, server, url, nil); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: got %d, want 400", url, w.Code)
+		}
+	}
+}
+
+func TestNear (t *testing.T) {
+	server, _ := testServer(t)
+
+	w := get(t, server, "/stations/near?lat=39.0461&lon=-95.68&radius=5000", nil)
+	p, pids := pagePIDs(t, w)
+
+	if p.Total != 2 || pids[0] != "KV0001" || p.Stations[0].Distance == nil || *p.Stations[0].Distance > *p.Stations[1].Distance {
+		t.Errorf("got %s", w.Body.String())
+	}
+
+	if w := get(t, server, "/stations/near?lat=39&lon=-95.7&radius=1", nil); w.Code != http.StatusOK {
+		t.Errorf("got %d for an empty result", w.Code)
+	}
+
+	if w := get(t, server, "/stations/near?lon=-95.7", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("got %d without a lat, want 400", w.Code)
+	}
+}
+
+func TestETagAndGzip (t *testing.T) {
+	server, _ := testServer(t)
+
+	w := get(t, server, "/stations/HV4612", nil)
+	etag := w.Header().Get("ETag")
+
+	if etag == "" {
+		t.Fatal("no etag")
+	}
+
+	if w := get(t, server, "/stations/HV4612", map[string]string{"If-None-Match": etag}); w.Code != http.StatusNotModified {
+		t.Errorf("got %d with a matching etag, want 304", w.Code)
+	}
+
+	if w := get(t, server, "/stations/KV0001", map[string]string{"If-None-Match": etag}); w.Code != http.StatusOK {
+		t.Errorf("got %d with the etag of another station", w.Code)
+	}
+
+	zipped := get(t, server, "/stations/HV4612", map[string]string{"Accept-Encoding": "gzip, deflate"})
+
+	if zipped.Header().Get("Content-Encoding") != "gzip" || zipped.Header().Get("ETag") != etag {
+		t.Fatalf("got encoding %q and etag %q", zipped.Header().Get("Content-Encoding"), zipped.Header().Get("ETag"))
+	}
+
+	r, err := gzip.NewReader(zipped.Body)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := io.ReadAll(r)
+
+	if !bytes.Equal(body, w.Body.Bytes()) {
+		t.Error("the gzipped body is not the same as the plain one")
+	}
+
+	if w := get(t, server, "/stations/HV4612", map[string]string{"Accept-Encoding": "gzip;q=0"}); w.Header().Get("Content-Encoding") != "" {
+		t.Error("gzipped when the client turned it off")
+	}
+}
+
+func TestWatch (t *testing.T) {
+	server, paths := testServer(t)
+	server.Logf = func (format string, args ...interface{}) {}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go server.Watch(ctx, 10 * time.Millisecond)
+
+	// drop every file but the first order one
+	for _, path := range paths {
+		if filepath.Base(path) != "first_order.txt" {
+			if err := os.WriteFile(path, nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for server.Archive().Len() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("still %d sheets after the files changed", server.Archive().Len())
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if w := get(t, server, "/stations/KV0001", nil); w.Code != http.StatusNotFound {
+		t.Errorf("got %d for a station that was removed", w.Code)
+	}
+
+	// a file that can not be read keeps the old archive
+	os.Remove(paths[0])
+	time.Sleep(50 * time.Millisecond)
+
+	if server.Archive().Len() != 1 {
+		t.Errorf("got %d sheets after a failed reload, want 1", server.Archive().Len())
+	}
+}
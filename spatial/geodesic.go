@@ -0,0 +1,95 @@
+package spatial
+
+import (
+	"math"
+)
+
+// the grs 80 ellipsoid of nad 83
+var (
+	semiMajor = 6378137.0
+	flattening = 1 / 298.257222101
+	semiMinor = semiMajor * (1 - flattening)
+
+	// the radius of curvature of the meridian at the equator, the smallest anywhere on the
+	// ellipsoid, so a sphere of it never gives a longer distance than the ellipsoid
+	minRadius = semiMajor * (1 - flattening) * (1 - flattening)
+
+	// the mean radius, for the points vincenty can not solve
+	meanRadius = (2 * semiMajor + semiMinor) / 3
+)
+
+// Distance is the geodesic distance in meters between two points on the grs 80 ellipsoid,
+// by the inverse formula of vincenty. nearly antipodal points, where it does not converge,
+// fall back to the great circle on a sphere of the mean radius
+func Distance (lat1 float64, lon1 float64, lat2 float64, lon2 float64) float64 {
+	if lat1 == lat2 && lon1 == lon2 {
+		return 0
+	}
+
+	f := flattening
+	l := radians(lon2 - lon1)
+	u1 := math.Atan((1 - f) * math.Tan(radians(lat1)))
+	u2 := math.Atan((1 - f) * math.Tan(radians(lat2)))
+	sinU1, cosU1 := math.Sincos(u1)
+	sinU2, cosU2 := math.Sincos(u2)
+
+	lambda := l
+
+	for i := 0; i < 200; i++ {
+		sinLambda, cosLambda := math.Sincos(lambda)
+
+		sinSigma := math.Hypot(cosU2 * sinLambda, cosU1 * sinU2 - sinU1 * cosU2 * cosLambda)
+
+		if sinSigma == 0 {
+			return 0
+		}
+
+		cosSigma := sinU1 * sinU2 + cosU1 * cosU2 * cosLambda
+		sigma := math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cosSqAlpha := 1 - sinAlpha * sinAlpha
+
+		// both points on the equator
+		cos2SigmaM := 0.0
+
+		if cosSqAlpha != 0 {
+			cos2SigmaM = cosSigma - 2 * sinU1 * sinU2 / cosSqAlpha
+		}
+
+		c := f / 16 * cosSqAlpha * (4 + f * (4 - 3 * cosSqAlpha))
+		previous := lambda
+		lambda = l + (1 - c) * f * sinAlpha * (sigma + c * sinSigma * (cos2SigmaM + c * cosSigma * (-1 + 2 * cos2SigmaM * cos2SigmaM)))
+
+		if math.Abs(lambda - previous) < 1e-12 {
+			uSq := cosSqAlpha * (semiMajor * semiMajor - semiMinor * semiMinor) / (semiMinor * semiMinor)
+			a := 1 + uSq / 16384 * (4096 + uSq * (-768 + uSq * (320 - 175 * uSq)))
+			b := uSq / 1024 * (256 + uSq * (-128 + uSq * (74 - 47 * uSq)))
+			deltaSigma := b * sinSigma * (cos2SigmaM + b / 4 * (cosSigma * (-1 + 2 * cos2SigmaM * cos2SigmaM) - b / 6 * cos2SigmaM * (-3 + 4 * sinSigma * sinSigma) * (-3 + 4 * cos2SigmaM * cos2SigmaM)))
+
+			return semiMinor * a * (sigma - deltaSigma)
+		}
+	}
+
+	return greatCircle(lat1, lon1, lat2, lon2, meanRadius)
+}
+
+// haversine distance on a sphere of the radius
+func greatCircle (lat1 float64, lon1 float64, lat2 float64, lon2 float64, radius float64) float64 {
+	h := haversine(radians(lat2 - lat1)) + math.Cos(radians(lat1)) * math.Cos(radians(lat2)) * haversine(radians(lon2 - lon1))
+	return 2 * radius * math.Asin(math.Sqrt(math.Min(1, h)))
+}
+
+func haversine (theta float64) float64 {
+	s := math.Sin(theta / 2)
+	return s * s
+}
+
+func radians (degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+// the smallest angle between two lons in degrees, 179 and -179 are 2 apart
+func lonDifference (a float64, b float64) float64 {
+	d := math.Mod(math.Abs(a - b), 360)
+	return math.Min(d, 360 - d)
+}
@@ -0,0 +1,76 @@
+package spatial
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// version of the saved index, bumped when the layout changes
+var fileVersion = 1
+
+// ErrVersion is returned when loading an index saved by another version of the package
+var ErrVersion = errors.New("unsupported index version")
+
+// what is written to disk, the tree is saved as built so loading does not pack it again
+type indexFile struct {
+	Version int
+	Marks []Mark
+	Nodes []node
+}
+
+// Save builds the index and writes it to w
+func (index *Index) Save (w io.Writer) error {
+	index.Build()
+
+	return gob.NewEncoder(w).Encode(indexFile{
+		Version: fileVersion,
+		Marks: index.marks,
+		Nodes: index.nodes,
+	})
+}
+
+// SaveFile saves the index to the file at path, replacing it
+func (index *Index) SaveFile (path string) error {
+	file, err := os.Create(path)
+
+	if err != nil {
+		return err
+	}
+
+	if err := index.Save(file); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// Load reads an index written by Save
+func Load (r io.Reader) (*Index, error) {
+	var f indexFile
+
+	if err := gob.NewDecoder(r).Decode(&f); err != nil {
+		return nil, err
+	}
+
+	if f.Version != fileVersion {
+		return nil, fmt.Errorf("%w %d, want %d", ErrVersion, f.Version, fileVersion)
+	}
+
+	return &Index{marks: f.Marks, nodes: f.Nodes}, nil
+}
+
+// LoadFile reads an index saved with SaveFile
+func LoadFile (path string) (*Index, error) {
+	file, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+	return Load(file)
+}
@@ -0,0 +1,219 @@
+package spatial
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+// most children of a node
+var nodeSize = 16
+
+// a node of the tree, its children are the nodes or, for a leaf, the marks First to First + Count
+type node struct {
+	Box box
+	Leaf bool
+	First int
+	Count int
+}
+
+// in decimal degrees, the lon can go past 180 for a query across the antimeridian
+type box struct {
+	MinLat float64
+	MinLon float64
+	MaxLat float64
+	MaxLon float64
+}
+
+// Build packs the marks into the tree with sort tile recursive, the queries call it when the
+// marks changed. the marks are reordered
+func (index *Index) Build () {
+	if len(index.nodes) > 0 || len(index.marks) == 0 {
+		return
+	}
+
+	marks := make([]Mark, len(index.marks))
+
+	for i, j := range tileOrder(len(index.marks), func (i int) (float64, float64) {
+		return index.marks[i].Lat, index.marks[i].Lon
+	}) {
+		marks[i] = index.marks[j]
+	}
+
+	index.marks = marks
+	level := make([]node, 0, len(marks) / nodeSize + 1)
+
+	for first := 0; first < len(marks); first += nodeSize {
+		n := node{Leaf: true, First: first, Count: min(nodeSize, len(marks) - first)}
+		n.Box = box{MinLat: marks[first].Lat, MinLon: marks[first].Lon, MaxLat: marks[first].Lat, MaxLon: marks[first].Lon}
+
+		for _, mark := range marks[first:first + n.Count] {
+			n.Box = n.Box.extend(box{MinLat: mark.Lat, MinLon: mark.Lon, MaxLat: mark.Lat, MaxLon: mark.Lon})
+		}
+
+		level = append(level, n)
+	}
+
+	nodes := make([]node, 0, len(level) * 2)
+
+	// every level is packed into the one above it until there is only the root
+	for len(level) > 1 {
+		sorted := make([]node, len(level))
+
+		for i, j := range tileOrder(len(level), func (i int) (float64, float64) {
+			return (level[i].Box.MinLat + level[i].Box.MaxLat) / 2, (level[i].Box.MinLon + level[i].Box.MaxLon) / 2
+		}) {
+			sorted[i] = level[j]
+		}
+
+		start := len(nodes)
+		nodes = append(nodes, sorted...)
+		level = level[:0]
+
+		for first := 0; first < len(sorted); first += nodeSize {
+			n := node{First: start + first, Count: min(nodeSize, len(sorted) - first), Box: sorted[first].Box}
+
+			for _, child := range sorted[first:first + n.Count] {
+				n.Box = n.Box.extend(child.Box)
+			}
+
+			level = append(level, n)
+		}
+	}
+
+	index.nodes = append(nodes, level[0])
+}
+
+// the order that puts items near each other in the same node, sorted into vertical
+// slices by lon and each slice by lat
+func tileOrder (n int, center func (i int) (float64, float64)) []int {
+	order := make([]int, n)
+
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func (i, j int) bool {
+		_, a := center(order[i])
+		_, b := center(order[j])
+		return a < b
+	})
+
+	nodes := int(math.Ceil(float64(n) / float64(nodeSize)))
+	slice := int(math.Ceil(math.Sqrt(float64(nodes)))) * nodeSize
+
+	for first := 0; first < n; first += slice {
+		part := order[first:min(first + slice, n)]
+
+		sort.SliceStable(part, func (i, j int) bool {
+			a, _ := center(part[i])
+			b, _ := center(part[j])
+			return a < b
+		})
+	}
+
+	return order
+}
+
+func min (a int, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func (b box) extend (o box) box {
+	return box{
+		MinLat: math.Min(b.MinLat, o.MinLat),
+		MinLon: math.Min(b.MinLon, o.MinLon),
+		MaxLat: math.Max(b.MaxLat, o.MaxLat),
+		MaxLon: math.Max(b.MaxLon, o.MaxLon),
+	}
+}
+
+// the lon ranges of a query box, two when it crosses the antimeridian
+func (b box) lonRanges () [][2]float64 {
+	if b.MinLon <= b.MaxLon {
+		return [][2]float64{{b.MinLon, b.MaxLon}}
+	}
+
+	return [][2]float64{{b.MinLon, 180}, {-180, b.MaxLon}}
+}
+
+func (b box) contains (lat float64, lon float64) bool {
+	if lat < b.MinLat || lat > b.MaxLat {
+		return false
+	}
+
+	for _, r := range b.lonRanges() {
+		if lon >= r[0] && lon <= r[1] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// true when the node box o overlaps the query box b
+func (b box) intersects (o box) bool {
+	if o.MaxLat < b.MinLat || o.MinLat > b.MaxLat {
+		return false
+	}
+
+	for _, r := range b.lonRanges() {
+		if o.MaxLon >= r[0] && o.MinLon <= r[1] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// a distance in meters no greater than the geodesic distance from the point to anywhere in the box
+func (b box) minDistance (lat float64, lon float64) float64 {
+	dLat := 0.0
+
+	if lat < b.MinLat {
+		dLat = b.MinLat - lat
+	} else if lat > b.MaxLat {
+		dLat = lat - b.MaxLat
+	}
+
+	dLon := 0.0
+
+	if lon < b.MinLon || lon > b.MaxLon {
+		dLon = math.Min(lonDifference(lon, b.MinLon), lonDifference(lon, b.MaxLon))
+	}
+
+	// haversine with the least cos of the box lats, each term is as small as it can be in the box
+	cosLat := math.Min(math.Cos(radians(b.MinLat)), math.Cos(radians(b.MaxLat)))
+	h := haversine(radians(dLat)) + math.Cos(radians(lat)) * cosLat * haversine(radians(dLon))
+
+	return 2 * minRadius * math.Asin(math.Sqrt(math.Min(1, h)))
+}
+
+// the nodes and marks still to look at by how close they can be
+type searchItem struct {
+	node int
+	mark bool
+	distance float64
+}
+
+type searchQueue []searchItem
+
+func (q searchQueue) Len () int { return len(q) }
+func (q searchQueue) Less (i, j int) bool { return q[i].distance < q[j].distance }
+func (q searchQueue) Swap (i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *searchQueue) Push (x interface{}) { *q = append(*q, x.(searchItem)) }
+
+func (q *searchQueue) Pop () interface{} {
+	old := *q
+	item := old[len(old) - 1]
+	*q = old[:len(old) - 1]
+
+	return item
+}
+
+func (q *searchQueue) push (item searchItem) { heap.Push(q, item) }
+func (q *searchQueue) pop () searchItem { return heap.Pop(q).(searchItem) }
@@ -0,0 +1,244 @@
+// Package spatial finds datasheet marks by where they are. An Index holds the NAD 83
+// position of every mark in an r-tree and answers nearest, radius and bounding box
+// queries with geodesic distances on the GRS 80 ellipsoid.
+//
+//	index := spatial.NewIndex()
+//	index.Add(sheet)
+//
+//	// the marks within 2 km of a point that were last found in good condition
+//	for _, result := range index.Radius(32.7157, -117.1611, 2000, spatial.Filter{Conditions: []string{"GOOD"}}) {
+//		fmt.Println(result.PID, result.Distance)
+//	}
+package spatial
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/carterharrison/dsdata/datasheet"
+)
+
+// Mark is the part of a sheet the index keeps
+type Mark struct {
+	PID datasheet.PID `json:"pid"`
+	Designation string `json:"designation"`
+
+	// nad 83 decimal degrees, south and west are negative
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+
+	Marker datasheet.Marker `json:"marker,omitempty"`
+	Stability datasheet.Stability `json:"stability,omitempty"`
+
+	// of the latest history row, ex: GOOD, NOT FOUND
+	Condition string `json:"condition,omitempty"`
+	ConditionDate string `json:"conditionDate,omitempty"`
+}
+
+// MarkOf is the mark of a sheet, false when the sheet has no pid or position
+func MarkOf (sheet datasheet.DataSheet) (Mark, bool) {
+	if sheet.Id == "" || sheet.Position == nil {
+		return Mark{}, false
+	}
+
+	mark := Mark{
+		PID: datasheet.PID(sheet.Id),
+		Designation: sheet.BasicMetadata["DESIGNATION"],
+		Lat: sheet.Position.Lat,
+		Lon: sheet.Position.Lon,
+	}
+
+	if sheet.Monument != nil {
+		mark.Marker = sheet.Monument.MarkerType
+		mark.Stability = sheet.Monument.StabilityType
+	}
+
+	if history, ok := sheet.LatestHistory(); ok {
+		mark.Condition = history.Condition
+		mark.ConditionDate = history.Date
+	}
+
+	return mark, true
+}
+
+// Result is a mark found by a query
+type Result struct {
+	Mark
+
+	// geodesic distance from the point of the query in meters
+	Distance float64 `json:"distance"`
+}
+
+// Filter picks the marks a query returns, an empty list lets every mark through
+type Filter struct {
+	Markers []datasheet.Marker
+	Stabilities []datasheet.Stability
+
+	// the condition of the latest history row, the case does not matter
+	Conditions []string
+}
+
+// Match is true when the mark passes every list of the filter
+func (filter Filter) Match (mark Mark) bool {
+	if len(filter.Markers) > 0 && !containsFold(len(filter.Markers), func (i int) string { return string(filter.Markers[i]) }, string(mark.Marker)) {
+		return false
+	}
+
+	if len(filter.Stabilities) > 0 && !containsFold(len(filter.Stabilities), func (i int) string { return string(filter.Stabilities[i]) }, string(mark.Stability)) {
+		return false
+	}
+
+	if len(filter.Conditions) > 0 && !containsFold(len(filter.Conditions), func (i int) string { return filter.Conditions[i] }, mark.Condition) {
+		return false
+	}
+
+	return true
+}
+
+func containsFold (n int, item func (i int) string, s string) bool {
+	for i := 0; i < n; i++ {
+		if strings.EqualFold(strings.TrimSpace(item(i)), s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Index is an r-tree of marks. it is packed in one go when first queried after an Add,
+// call Build before sharing it between goroutines
+type Index struct {
+	marks []Mark
+
+	// the tree, the root is the last node. empty when it needs to be built
+	nodes []node
+}
+
+func NewIndex () *Index {
+	return &Index{}
+}
+
+// Add puts the mark of the sheet in the index, false when the sheet has no pid or position
+func (index *Index) Add (sheet datasheet.DataSheet) bool {
+	mark, ok := MarkOf(sheet)
+
+	if ok {
+		index.AddMark(mark)
+	}
+
+	return ok
+}
+
+func (index *Index) AddMark (mark Mark) {
+	index.marks = append(index.marks, mark)
+	index.nodes = nil
+}
+
+// Len is the number of marks in the index
+func (index *Index) Len () int {
+	return len(index.marks)
+}
+
+// Nearest is the k marks closest to the point that pass the filter, the closest first
+func (index *Index) Nearest (lat float64, lon float64, k int, filter Filter) []Result {
+	index.Build()
+	results := make([]Result, 0, k)
+
+	if k <= 0 || len(index.nodes) == 0 {
+		return results
+	}
+
+	queue := &searchQueue{}
+	queue.push(searchItem{node: len(index.nodes) - 1, distance: 0})
+
+	for queue.Len() > 0 && len(results) < k {
+		item := queue.pop()
+
+		if item.mark {
+			results = append(results, Result{Mark: index.marks[item.node], Distance: item.distance})
+			continue
+		}
+
+		n := index.nodes[item.node]
+
+		for i := n.First; i < n.First + n.Count; i++ {
+			if !n.Leaf {
+				queue.push(searchItem{node: i, distance: index.nodes[i].Box.minDistance(lat, lon)})
+			} else if filter.Match(index.marks[i]) {
+				queue.push(searchItem{node: i, mark: true, distance: Distance(lat, lon, index.marks[i].Lat, index.marks[i].Lon)})
+			}
+		}
+	}
+
+	return results
+}
+
+// Radius is every mark within meters of the point that passes the filter, the closest first
+func (index *Index) Radius (lat float64, lon float64, meters float64, filter Filter) []Result {
+	index.Build()
+	results := make([]Result, 0)
+
+	index.walk(func (b box) bool {
+		return b.minDistance(lat, lon) <= meters
+	}, func (mark Mark) {
+		if !filter.Match(mark) {
+			return
+		}
+
+		if d := Distance(lat, lon, mark.Lat, mark.Lon); d <= meters {
+			results = append(results, Result{Mark: mark, Distance: d})
+		}
+	})
+
+	sort.SliceStable(results, func (i, j int) bool {
+		return results[i].Distance < results[j].Distance
+	})
+
+	return results
+}
+
+// Box is every mark in the bounding box that passes the filter, by pid. a box with a
+// min lon east of its max lon crosses the antimeridian
+func (index *Index) Box (minLat float64, minLon float64, maxLat float64, maxLon float64, filter Filter) []Mark {
+	index.Build()
+	query := box{MinLat: minLat, MinLon: minLon, MaxLat: maxLat, MaxLon: maxLon}
+	marks := make([]Mark, 0)
+
+	index.walk(query.intersects, func (mark Mark) {
+		if query.contains(mark.Lat, mark.Lon) && filter.Match(mark) {
+			marks = append(marks, mark)
+		}
+	})
+
+	sort.Slice(marks, func (i, j int) bool {
+		return marks[i].PID < marks[j].PID
+	})
+
+	return marks
+}
+
+// calls fn with the marks of every leaf whose box is wanted
+func (index *Index) walk (want func (b box) bool, fn func (mark Mark)) {
+	if len(index.nodes) == 0 {
+		return
+	}
+
+	stack := []int{len(index.nodes) - 1}
+
+	for len(stack) > 0 {
+		n := index.nodes[stack[len(stack) - 1]]
+		stack = stack[:len(stack) - 1]
+
+		if !want(n.Box) {
+			continue
+		}
+
+		for i := n.First; i < n.First + n.Count; i++ {
+			if n.Leaf {
+				fn(index.marks[i])
+			} else {
+				stack = append(stack, i)
+			}
+		}
+	}
+}
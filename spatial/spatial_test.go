@@ -0,0 +1,264 @@
+package spatial
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/carterharrison/dsdata/datasheet"
+)
+
+// marks spread around san diego, every fifth one destroyed and every third a bolt
+func testMarks (n int) []Mark {
+	random := rand.New(rand.NewSource(1))
+	marks := make([]Mark, n)
+
+	for i := range marks {
+		marks[i] = Mark{
+			PID: datasheet.PID(string(rune('A' + i / 26000 % 26)) + string(rune('A' + i / 1000 % 26)) + padPID(i % 1000)),
+			Lat: 32.5 + random.Float64() * 0.5,
+			Lon: -117.4 + random.Float64() * 0.5,
+			Marker: datasheet.MarkerSurveyDisk,
+			Stability: datasheet.StabilityMayHold,
+			Condition: "GOOD",
+		}
+
+		if i % 3 == 0 {
+			marks[i].Marker = datasheet.MarkerBolt
+		}
+
+		if i % 5 == 0 {
+			marks[i].Condition = "NOT FOUND"
+		}
+	}
+
+	return marks
+}
+
+func padPID (i int) string {
+	s := []byte("0000")
+
+	for p := 3; i > 0; p-- {
+		s[p] = byte('0' + i % 10)
+		i /= 10
+	}
+
+	return string(s)
+}
+
+func testIndex (marks []Mark) *Index {
+	index := NewIndex()
+
+	for _, mark := range marks {
+		index.AddMark(mark)
+	}
+
+	return index
+}
+
+// every mark that passes the filter with its distance, the closest first
+func bruteForce (marks []Mark, lat float64, lon float64, filter Filter) []Result {
+	results := make([]Result, 0)
+
+	for _, mark := range marks {
+		if filter.Match(mark) {
+			results = append(results, Result{Mark: mark, Distance: Distance(lat, lon, mark.Lat, mark.Lon)})
+		}
+	}
+
+	sort.SliceStable(results, func (i, j int) bool {
+		return results[i].Distance < results[j].Distance
+	})
+
+	return results
+}
+
+func distances (results []Result) []float64 {
+	out := make([]float64, len(results))
+
+	for i, result := range results {
+		out[i] = result.Distance
+	}
+
+	return out
+}
+
+func TestDistance (t *testing.T) {
+	// flinders peak to buninyong, the example of vincenty's paper on grs 80
+	got := Distance(-37.951033416666665, 144.42486788888888, -37.65282113888889, 143.92649552777777)
+
+	if math.Abs(got - 54972.271) > 0.001 {
+		t.Errorf("got %f, want 54972.271", got)
+	}
+
+	if got := Distance(10, 20, 10, 20); got != 0 {
+		t.Errorf("got %f for the same point", got)
+	}
+
+	// nearly antipodal, where vincenty may fall back to the sphere
+	if got := Distance(0, 0, 0.5, 179.7); math.IsNaN(got) || got < 19000000 {
+		t.Errorf("got %f for nearly antipodal points", got)
+	}
+}
+
+func TestMinDistance (t *testing.T) {
+	random := rand.New(rand.NewSource(2))
+
+	for i := 0; i < 10000; i++ {
+		b := box{MinLat: random.Float64() * 160 - 80, MinLon: random.Float64() * 340 - 170}
+		b.MaxLat = math.Min(80, b.MinLat + random.Float64() * 5)
+		b.MaxLon = math.Min(180, b.MinLon + random.Float64() * 5)
+
+		lat, lon := random.Float64() * 160 - 80, random.Float64() * 360 - 180
+		inLat, inLon := b.MinLat + random.Float64() * (b.MaxLat - b.MinLat), b.MinLon + random.Float64() * (b.MaxLon - b.MinLon)
+
+		if bound, d := b.minDistance(lat, lon), Distance(lat, lon, inLat, inLon); bound > d {
+			t.Fatalf("bound %f is over the distance %f from %f %f to %f %f in %+v", bound, d, lat, lon, inLat, inLon, b)
+		}
+	}
+}
+
+func TestNearest (t *testing.T) {
+	marks := testMarks(5000)
+	index := testIndex(marks)
+
+	filters := []Filter{
+		{},
+		{Markers: []datasheet.Marker{datasheet.MarkerBolt}},
+		{Conditions: []string{"good"}, Stabilities: []datasheet.Stability{datasheet.StabilityMayHold}},
+	}
+
+	for _, filter := range filters {
+		for _, point := range [][2]float64{{32.7, -117.1}, {33.5, -116}, {32.5, -117.4}} {
+			got := index.Nearest(point[0], point[1], 10, filter)
+			want := bruteForce(marks, point[0], point[1], filter)[:10]
+
+			if !reflect.DeepEqual(distances(got), distances(want)) {
+				t.Errorf("Nearest(%v, %+v) = %v, want %v", point, filter, distances(got), distances(want))
+			}
+
+			for _, result := range got {
+				if !filter.Match(result.Mark) {
+					t.Errorf("%s does not pass %+v", result.PID, filter)
+				}
+			}
+		}
+	}
+
+	if got := index.Nearest(32.7, -117.1, 0, Filter{}); len(got) != 0 {
+		t.Errorf("got %d marks for k 0", len(got))
+	}
+
+	if got := NewIndex().Nearest(32.7, -117.1, 5, Filter{}); len(got) != 0 {
+		t.Errorf("got %d marks from an empty index", len(got))
+	}
+}
+
+func TestRadius (t *testing.T) {
+	marks := testMarks(5000)
+	index := testIndex(marks)
+	filter := Filter{Conditions: []string{"GOOD"}}
+
+	got := index.Radius(32.7, -117.1, 2000, filter)
+	want := make([]Result, 0)
+
+	for _, result := range bruteForce(marks, 32.7, -117.1, filter) {
+		if result.Distance <= 2000 {
+			want = append(want, result)
+		}
+	}
+
+	if len(want) == 0 || !reflect.DeepEqual(distances(got), distances(want)) {
+		t.Errorf("got %v, want %v", distances(got), distances(want))
+	}
+}
+
+func TestBox (t *testing.T) {
+	marks := testMarks(5000)
+	index := testIndex(marks)
+
+	got := index.Box(32.6, -117.2, 32.7, -117.1, Filter{})
+	want := 0
+
+	for _, mark := range marks {
+		if mark.Lat >= 32.6 && mark.Lat <= 32.7 && mark.Lon >= -117.2 && mark.Lon <= -117.1 {
+			want++
+		}
+	}
+
+	if want == 0 || len(got) != want {
+		t.Errorf("got %d marks, want %d", len(got), want)
+	}
+
+	// across the antimeridian
+	index = testIndex([]Mark{{PID: "AA0001", Lon: 179}, {PID: "AA0002", Lon: -179.9}, {PID: "AA0003", Lon: 0}})
+	box := index.Box(-1, 178.5, 1, -179, Filter{})
+
+	if len(box) != 2 || box[0].PID != "AA0001" || box[1].PID != "AA0002" {
+		t.Errorf("got %v across the antimeridian", box)
+	}
+
+	if got := index.Nearest(0, 179.95, 1, Filter{}); got[0].PID != "AA0002" {
+		t.Errorf("got %s nearest the antimeridian", got[0].PID)
+	}
+}
+
+func TestSaveLoad (t *testing.T) {
+	marks := testMarks(1000)
+	index := testIndex(marks)
+
+	var buf bytes.Buffer
+
+	if err := index.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(&buf)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(loaded.Nearest(32.7, -117.1, 20, Filter{}), index.Nearest(32.7, -117.1, 20, Filter{})) {
+		t.Error("the loaded index does not answer like the saved one")
+	}
+
+	if len(loaded.nodes) != len(index.nodes) {
+		t.Errorf("the loaded index has %d nodes, want %d", len(loaded.nodes), len(index.nodes))
+	}
+}
+
+func TestMarkOf (t *testing.T) {
+	sheet := datasheet.DataSheet{
+		Id: "HV4612",
+		BasicMetadata: map[string]string{"DESIGNATION": "MOUNT HOPE"},
+		Position: &datasheet.Position{Lat: 32.718198, Lon: -117.138927},
+		Monument: &datasheet.Monument{MarkerType: datasheet.MarkerSurveyDisk, StabilityType: datasheet.StabilityMostReliable},
+		History: []datasheet.History{{Date: "1934", Condition: "MONUMENTED"}, {Date: "20080312", Condition: "GOOD"}},
+	}
+
+	got, ok := MarkOf(sheet)
+	want := Mark{
+		PID: "HV4612",
+		Designation: "MOUNT HOPE",
+		Lat: 32.718198,
+		Lon: -117.138927,
+		Marker: datasheet.MarkerSurveyDisk,
+		Stability: datasheet.StabilityMostReliable,
+		Condition: "GOOD",
+		ConditionDate: "20080312",
+	}
+
+	if !ok || got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	sheet.Position = nil
+
+	if _, ok := MarkOf(sheet); ok {
+		t.Error("got a mark for a sheet without a position")
+	}
+}
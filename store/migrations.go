@@ -0,0 +1,200 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrNewerSchema is returned when opening a database migrated by a newer version of the package
+var ErrNewerSchema = errors.New("database schema is newer than this version of dsdata")
+
+// each migration moves the schema up a version, the version the database is at is kept in
+// PRAGMA user_version. a released migration is never changed, add a new one to the end
+var migrations = []string{
+	// 1: the tables of a sheet
+	`
+	CREATE TABLE stations (
+		id TEXT PRIMARY KEY,
+		designation TEXT,
+		state_county TEXT,
+		country TEXT,
+		usgs_quad TEXT,
+
+		lat REAL,
+		lon REAL,
+		datum TEXT,
+		realization TEXT,
+		epoch REAL,
+		position_source TEXT,
+
+		ellipsoid_height REAL,
+		ellipsoid_height_source TEXT,
+		orthometric_height REAL,
+		orthometric_datum TEXT,
+		orthometric_height_source TEXT,
+		geoid_height REAL,
+		geoid_model TEXT,
+		x REAL,
+		y REAL,
+		z REAL,
+		laplace_correction REAL,
+		deflection_xi REAL,
+		deflection_eta REAL,
+
+		spatial_address TEXT,
+		marker TEXT,
+		setting TEXT,
+		stability TEXT,
+		description TEXT,
+
+		last_condition TEXT,
+		last_condition_date TEXT
+	);
+
+	CREATE INDEX stations_designation ON stations (designation);
+	CREATE INDEX stations_lat_lon ON stations (lat, lon);
+
+	CREATE TABLE survey_control (
+		station_id TEXT NOT NULL REFERENCES stations (id),
+		seq INTEGER NOT NULL,
+		superseded INTEGER NOT NULL,
+		item TEXT,
+		value TEXT,
+		by TEXT,
+		PRIMARY KEY (station_id, superseded, seq)
+	);
+
+	CREATE TABLE accuracy (
+		station_id TEXT NOT NULL REFERENCES stations (id),
+		kind TEXT NOT NULL,
+		seq INTEGER NOT NULL,
+		raw TEXT,
+		order_code TEXT,
+		class TEXT,
+		PRIMARY KEY (station_id, kind, seq)
+	);
+
+	CREATE TABLE network_accuracy (
+		station_id TEXT NOT NULL REFERENCES stations (id),
+		seq INTEGER NOT NULL,
+		horiz REAL,
+		ellip REAL,
+		sdn REAL,
+		sde REAL,
+		sdh REAL,
+		corr_ne REAL,
+		PRIMARY KEY (station_id, seq)
+	);
+
+	CREATE TABLE spc (
+		station_id TEXT NOT NULL REFERENCES stations (id),
+		seq INTEGER NOT NULL,
+		system TEXT,
+		zone TEXT,
+		north REAL,
+		east REAL,
+		units TEXT,
+		north_meters REAL,
+		east_meters REAL,
+		scale REAL,
+		convergence REAL,
+		estimated TEXT,
+		PRIMARY KEY (station_id, seq)
+	);
+
+	CREATE TABLE history (
+		station_id TEXT NOT NULL REFERENCES stations (id),
+		seq INTEGER NOT NULL,
+		date TEXT,
+		condition TEXT,
+		by TEXT,
+		PRIMARY KEY (station_id, seq)
+	);
+
+	CREATE TABLE recoveries (
+		station_id TEXT NOT NULL REFERENCES stations (id),
+		seq INTEGER NOT NULL,
+		date TEXT,
+		description TEXT,
+		PRIMARY KEY (station_id, seq)
+	);
+
+	CREATE TABLE reference_objects (
+		station_id TEXT NOT NULL REFERENCES stations (id),
+		seq INTEGER NOT NULL,
+		pid TEXT,
+		ref TEXT,
+		distance TEXT,
+		distance_meters REAL,
+		geod_az TEXT,
+		azimuth REAL,
+		PRIMARY KEY (station_id, seq)
+	);
+
+	CREATE INDEX reference_objects_pid ON reference_objects (pid);
+
+	CREATE TABLE monumentation (
+		station_id TEXT NOT NULL REFERENCES stations (id),
+		key TEXT NOT NULL,
+		value TEXT,
+		PRIMARY KEY (station_id, key)
+	);
+	`,
+}
+
+// the tables with rows of a station, emptied before the station is written again
+var childTables = []string{
+	"survey_control",
+	"accuracy",
+	"network_accuracy",
+	"spc",
+	"history",
+	"recoveries",
+	"reference_objects",
+	"monumentation",
+}
+
+// runs the migrations the database does not have yet, each in a transaction of its own
+func migrate (db *sql.DB) error {
+	version, err := schemaVersion(db)
+
+	if err != nil {
+		return err
+	}
+
+	if version > len(migrations) {
+		return fmt.Errorf("%w: version %d, want at most %d", ErrNewerSchema, version, len(migrations))
+	}
+
+	for ; version < len(migrations); version++ {
+		tx, err := db.Begin()
+
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(migrations[version]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", version + 1, err)
+		}
+
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", version + 1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func schemaVersion (db *sql.DB) (int, error) {
+	version := 0
+	err := db.QueryRow("PRAGMA user_version").Scan(&version)
+
+	return version, err
+}
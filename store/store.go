@@ -0,0 +1,334 @@
+// Package store keeps parsed datasheets in a sqlite database, one table per repeated part
+// of a sheet keyed on the pid of its station. sqlite is embedded in pure go so nothing
+// else has to be installed, and the file can be opened by any sqlite client.
+//
+//	db, _ := store.Open("datasheets.db")
+//	defer db.Close()
+//
+//	db.Put(sheets...)
+//	rows, _ := db.DB().Query("SELECT id FROM stations WHERE last_condition = 'GOOD'")
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/carterharrison/dsdata/datasheet"
+
+	// registers the sqlite driver
+	_ "modernc.org/sqlite"
+)
+
+// Store is a sqlite database of sheets
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens or makes the database at path and migrates it to the schema of this version
+func Open (path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// sqlite takes one writer at a time
+	db.SetMaxOpenConns(1)
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Store{db: db}, nil
+}
+
+func (store *Store) Close () error {
+	return store.db.Close()
+}
+
+// DB is the database to query
+func (store *Store) DB () *sql.DB {
+	return store.db
+}
+
+// SchemaVersion is the migration the database is at
+func (store *Store) SchemaVersion () (int, error) {
+	return schemaVersion(store.db)
+}
+
+// Put writes the sheets in one transaction. a station already in the database is replaced,
+// so putting the same sheets again leaves it as it was
+func (store *Store) Put (sheets ...datasheet.DataSheet) error {
+	tx, err := store.db.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	w := &txWriter{tx: tx, stmts: make(map[string]*sql.Stmt)}
+
+	for i := range sheets {
+		if err := w.put(&sheets[i]); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Count is the number of stations in the database
+func (store *Store) Count () (int, error) {
+	count := 0
+	err := store.db.QueryRow("SELECT COUNT(*) FROM stations").Scan(&count)
+
+	return count, err
+}
+
+// a transaction with its statements prepared once
+type txWriter struct {
+	tx *sql.Tx
+	stmts map[string]*sql.Stmt
+}
+
+func (w *txWriter) exec (query string, args ...interface{}) error {
+	stmt, ok := w.stmts[query]
+
+	if !ok {
+		prepared, err := w.tx.Prepare(query)
+
+		if err != nil {
+			return err
+		}
+
+		stmt = prepared
+		w.stmts[query] = stmt
+	}
+
+	_, err := stmt.Exec(args...)
+	return err
+}
+
+// INSERT INTO table (a, b) VALUES (?, ?)
+func (w *txWriter) insert (table string, columns []string, values ...interface{}) error {
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(columns, ", "), placeholders(len(columns)))
+	return w.exec(query, values...)
+}
+
+var stationColumns = []string{
+	"id", "designation", "state_county", "country", "usgs_quad",
+	"lat", "lon", "datum", "realization", "epoch", "position_source",
+	"ellipsoid_height", "ellipsoid_height_source", "orthometric_height", "orthometric_datum", "orthometric_height_source",
+	"geoid_height", "geoid_model", "x", "y", "z", "laplace_correction", "deflection_xi", "deflection_eta",
+	"spatial_address", "marker", "setting", "stability", "description",
+	"last_condition", "last_condition_date",
+}
+
+// the upsert of a station, every column but the id is set again on a conflict
+var upsertStation = func () string {
+	updates := make([]string, 0, len(stationColumns))
+
+	for _, column := range stationColumns[1:] {
+		updates = append(updates, column + " = excluded." + column)
+	}
+
+	return fmt.Sprintf("INSERT INTO stations (%s) VALUES (%s) ON CONFLICT (id) DO UPDATE SET %s",
+		strings.Join(stationColumns, ", "), placeholders(len(stationColumns)), strings.Join(updates, ", "))
+}()
+
+func (w *txWriter) put (sheet *datasheet.DataSheet) error {
+	if sheet.Id == "" {
+		return datasheet.ErrMissingId
+	}
+
+	if err := w.exec(upsertStation, stationValues(sheet)...); err != nil {
+		return fmt.Errorf("%s: %w", sheet.Id, err)
+	}
+
+	for _, table := range childTables {
+		if err := w.exec("DELETE FROM " + table + " WHERE station_id = ?", sheet.Id); err != nil {
+			return fmt.Errorf("%s: %w", sheet.Id, err)
+		}
+	}
+
+	if err := w.putChildren(sheet); err != nil {
+		return fmt.Errorf("%s: %w", sheet.Id, err)
+	}
+
+	return nil
+}
+
+func stationValues (sheet *datasheet.DataSheet) []interface{} {
+	values := map[string]interface{}{
+		"id": sheet.Id,
+		"designation": sheet.BasicMetadata["DESIGNATION"],
+		"state_county": sheet.BasicMetadata["STATE/COUNTY"],
+		"country": sheet.BasicMetadata["COUNTRY"],
+		"usgs_quad": sheet.BasicMetadata["USGS QUAD"],
+		"spatial_address": sheet.SpatialAddress,
+	}
+
+	if p := sheet.Position; p != nil {
+		values["lat"], values["lon"], values["datum"], values["realization"] = p.Lat, p.Lon, p.Datum, p.Realization
+		values["position_source"] = p.Source
+
+		if p.Epoch != 0 {
+			values["epoch"] = p.Epoch
+		}
+	}
+
+	if h := sheet.EllipsoidHeight; h != nil {
+		values["ellipsoid_height"], values["ellipsoid_height_source"] = h.Height, h.Source
+	}
+
+	if h := sheet.OrthometricHeight; h != nil {
+		values["orthometric_height"], values["orthometric_datum"], values["orthometric_height_source"] = h.Height, h.Datum, h.Source
+	}
+
+	if h := sheet.GeoidHeight; h != nil {
+		values["geoid_height"], values["geoid_model"] = h.Height, h.Model
+	}
+
+	if xyz := sheet.XYZ; xyz != nil {
+		values["x"], values["y"], values["z"] = xyz.X, xyz.Y, xyz.Z
+	}
+
+	if l := sheet.LaplaceCorrection; l != nil {
+		values["laplace_correction"] = l.Seconds
+	}
+
+	if d := sheet.DeflectionOfVertical; d != nil {
+		values["deflection_xi"], values["deflection_eta"] = d.Xi, d.Eta
+	}
+
+	if m := sheet.Monument; m != nil {
+		values["marker"], values["setting"], values["stability"] = nullString(string(m.MarkerType)), nullString(string(m.SettingType)), nullString(string(m.StabilityType))
+	}
+
+	if len(sheet.StationDescription) > 0 {
+		descriptions := make([]string, len(sheet.StationDescription))
+
+		for i, d := range sheet.StationDescription {
+			descriptions[i] = d.Description
+		}
+
+		values["description"] = strings.Join(descriptions, "\n")
+	}
+
+	if history, ok := sheet.LatestHistory(); ok {
+		values["last_condition"], values["last_condition_date"] = history.Condition, history.Date
+	}
+
+	// the columns not set are null
+	out := make([]interface{}, len(stationColumns))
+
+	for i, column := range stationColumns {
+		out[i] = values[column]
+	}
+
+	return out
+}
+
+func (w *txWriter) putChildren (sheet *datasheet.DataSheet) error {
+	id := sheet.Id
+
+	for superseded, surveys := range [][]datasheet.Survey{sheet.NewSurveyControl, sheet.OldSurveyControl} {
+		for i, s := range surveys {
+			if err := w.insert("survey_control", []string{"station_id", "seq", "superseded", "item", "value", "by"}, id, i, superseded, s.Item, s.Value, s.By); err != nil {
+				return err
+			}
+		}
+	}
+
+	accuracy := []struct {
+		kind string
+		raw []string
+		decoded []datasheet.OrderClass
+	}{
+		{"horizontal", sheet.Accuracy.HorzOrder, sheet.Accuracy.Horizontal},
+		{"ellipsoid", sheet.Accuracy.EllpOrder, sheet.Accuracy.Ellipsoid},
+		{"vertical", sheet.Accuracy.VertOrder, sheet.Accuracy.Vertical},
+	}
+
+	for _, a := range accuracy {
+		for i, raw := range a.raw {
+			var order, class interface{}
+
+			if i < len(a.decoded) {
+				order, class = string(a.decoded[i].Order), nullString(a.decoded[i].Class)
+			}
+
+			if err := w.insert("accuracy", []string{"station_id", "kind", "seq", "raw", "order_code", "class"}, id, a.kind, i, raw, order, class); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, n := range sheet.Accuracy.Network {
+		if err := w.insert("network_accuracy", []string{"station_id", "seq", "horiz", "ellip", "sdn", "sde", "sdh", "corr_ne"}, id, i, n.Horiz, n.Ellip, n.SDN, n.SDE, n.SDH, n.CorrNE); err != nil {
+			return err
+		}
+	}
+
+	for i, s := range sheet.StatePlaneCoordinates {
+		columns := []string{"station_id", "seq", "system", "zone", "north", "east", "units", "north_meters", "east_meters", "scale", "convergence", "estimated"}
+
+		if err := w.insert("spc", columns, id, i, s.System, s.Zone, s.North, s.East, s.Units, s.NorthMeters, s.EastMeters, s.Scale, s.Convergence, nullString(s.Estimated)); err != nil {
+			return err
+		}
+	}
+
+	for i, h := range sheet.History {
+		if err := w.insert("history", []string{"station_id", "seq", "date", "condition", "by"}, id, i, h.Date, h.Condition, h.By); err != nil {
+			return err
+		}
+	}
+
+	for i, r := range sheet.StationRecoveries {
+		if err := w.insert("recoveries", []string{"station_id", "seq", "date", "description"}, id, i, r.Date, r.Description); err != nil {
+			return err
+		}
+	}
+
+	for i, r := range sheet.ReferenceObjects {
+		var meters, azimuth interface{}
+
+		if r.Length != nil {
+			meters = r.Length.Meters
+		}
+
+		if r.Azimuth != nil {
+			azimuth = *r.Azimuth
+		}
+
+		columns := []string{"station_id", "seq", "pid", "ref", "distance", "distance_meters", "geod_az", "azimuth"}
+
+		if err := w.insert("reference_objects", columns, id, i, nullString(r.Pid), r.Ref, nullString(r.Distance), meters, nullString(r.GeodAz), azimuth); err != nil {
+			return err
+		}
+	}
+
+	for key, value := range sheet.Monumentation {
+		if err := w.insert("monumentation", []string{"station_id", "key", "value"}, id, key, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// "" => NULL
+func nullString (s string) interface{} {
+	if s == "" {
+		return nil
+	}
+
+	return s
+}
+
+// ?, ?, ?
+func placeholders (n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
@@ -0,0 +1,184 @@
+package store
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/carterharrison/dsdata/datasheet"
+)
+
+// every sheet of the datasheet test corpus
+func corpus (t *testing.T) []datasheet.DataSheet {
+	t.Helper()
+	paths, _ := filepath.Glob("../datasheet/testdata/*.txt")
+	sheets := make([]datasheet.DataSheet, 0)
+
+	for _, path := range paths {
+		file, err := os.Open(path)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r := datasheet.NewReader(file)
+
+		for {
+			sheet, err := r.Read()
+
+			if err == io.EOF {
+				break
+			}
+
+			if _, ok := err.(datasheet.ParseErrors); !ok && err != nil {
+				t.Fatal(err)
+			}
+
+			sheets = append(sheets, sheet)
+		}
+
+		file.Close()
+	}
+
+	return sheets
+}
+
+func tempStore (t *testing.T) (*Store, string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "datasheets.db")
+	store, err := Open(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return store, path
+}
+
+// rows in each table
+func counts (t *testing.T, store *Store) map[string]int {
+	t.Helper()
+	out := make(map[string]int)
+
+	for _, table := range append([]string{"stations"}, childTables...) {
+		count := 0
+
+		if err := store.DB().QueryRow("SELECT COUNT(*) FROM " + table).Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+
+		out[table] = count
+	}
+
+	return out
+}
+
+func TestPutIdempotent (t *testing.T) {
+	store, _ := tempStore(t)
+	defer store.Close()
+	sheets := corpus(t)
+
+	if err := store.Put(sheets...); err != nil {
+		t.Fatal(err)
+	}
+
+	first := counts(t, store)
+
+	if first["stations"] != len(sheets) {
+		t.Errorf("got %d stations, want %d", first["stations"], len(sheets))
+	}
+
+	for _, table := range childTables {
+		if first[table] == 0 {
+			t.Errorf("nothing was written to %s", table)
+		}
+	}
+
+	if err := store.Put(sheets...); err != nil {
+		t.Fatal(err)
+	}
+
+	for table, count := range counts(t, store) {
+		if count != first[table] {
+			t.Errorf("%s has %d rows after putting the sheets again, want %d", table, count, first[table])
+		}
+	}
+}
+
+func TestPutReplaces (t *testing.T) {
+	store, _ := tempStore(t)
+	defer store.Close()
+
+	sheet := datasheet.DataSheet{
+		Id: "HV4612",
+		BasicMetadata: map[string]string{"DESIGNATION": "MOUNT HOPE"},
+		History: []datasheet.History{{Date: "1934", Condition: "MONUMENTED"}, {Date: "20080312", Condition: "GOOD"}},
+	}
+
+	if err := store.Put(sheet); err != nil {
+		t.Fatal(err)
+	}
+
+	sheet.History = append(sheet.History, datasheet.History{Date: "20150101", Condition: "NOT FOUND"})
+	sheet.Position = &datasheet.Position{Lat: 32.718198, Lon: -117.138927}
+
+	if err := store.Put(sheet); err != nil {
+		t.Fatal(err)
+	}
+
+	var condition string
+	var lat float64
+	var rows int
+
+	row := store.DB().QueryRow("SELECT last_condition, lat, (SELECT COUNT(*) FROM history WHERE station_id = id) FROM stations WHERE id = ?", "HV4612")
+
+	if err := row.Scan(&condition, &lat, &rows); err != nil {
+		t.Fatal(err)
+	}
+
+	if condition != "NOT FOUND" || lat != 32.718198 || rows != 3 {
+		t.Errorf("got %s %f %d, want NOT FOUND 32.718198 3", condition, lat, rows)
+	}
+
+	if err := store.Put(datasheet.DataSheet{}); err != datasheet.ErrMissingId {
+		t.Errorf("got %v for a sheet without a pid, want %v", err, datasheet.ErrMissingId)
+	}
+}
+
+func TestMigrations (t *testing.T) {
+	store, path := tempStore(t)
+
+	if version, err := store.SchemaVersion(); err != nil || version != len(migrations) {
+		t.Errorf("got version %d %v, want %d", version, err, len(migrations))
+	}
+
+	if err := store.Put(corpus(t)...); err != nil {
+		t.Fatal(err)
+	}
+
+	store.Close()
+
+	// opening it again does not run the migrations again
+	store, err := Open(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count, err := store.Count(); err != nil || count == 0 {
+		t.Errorf("got %d stations %v after opening again", count, err)
+	}
+
+	if _, err := store.DB().Exec("PRAGMA user_version = 1000"); err != nil {
+		t.Fatal(err)
+	}
+
+	store.Close()
+
+	if _, err := Open(path); err == nil {
+		t.Error("opened a database with a newer schema")
+	}
+}